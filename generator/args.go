@@ -1,23 +1,146 @@
 package generator
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
 )
 
 type options struct {
-	dataSourceName string
-	tableNames     []string
-	tags           []string
-	forceCases     []string
+	driverName string
+	outputPath string
+	// whether the output path is a subdirectory named after the database, with several data source names
+	dbSubdirectory      bool
+	packageName         string
+	dataSourceName      string
+	tls                 tlsOptions
+	stdout              *sourceFile
+	dryRun              bool
+	perPackage          bool
+	changed             bool
+	overwrite           string
+	proto               *protoFile
+	template            *template.Template
+	registry            *registry
+	manifest            *manifest
+	gitAttributes       bool
+	tableNames          []string
+	maxTables           int
+	tags                []string
+	forceCases          []string
+	schemas             []string
+	ambiguousTableNames map[string]bool
+	embedded            *embeddedStruct
+	views               bool
+	relations           bool
+	viewNames           map[string]bool
+	trimPrefixes        []string
+	singularize         bool
+	structPrefix        string
+	structSuffix        string
+	fileCase            string
+	fieldOrder          string
+	tableNameMode       string
+	noTableName         []string
+	tableNameFunc       string
+	jsonType            string
+	yearType            string
+	dateType            string
+	timeType            timeType
+	timeOnly            bool
+	decimal             bool
+	nullable            string
+	nullPointer         []string
+	boolColumns         bool
+	float32             bool
+	allPointers         bool
+	netTypes            bool
+	enums               bool
+	preserveNames       bool
+	commentTrim         *regexp.Regexp
+	noComments          bool
+	stringer            bool
+	orm                 string
+	jsonMethods         bool
+	jsonStringInts      bool
+	omitEmpty           bool
+	diffMethods         bool
+	constructors        bool
+	sqlHelpers          bool
+	fieldMap            bool
+	quoteIdentifiers    bool
+	onUnknown           string
+	emptyInterface      string
+	buildConstraints    []string
+	header              string
+	headerReplace       bool
+	marker              string
+	goGenerate          *Config
+	typeOverrides       map[string]string
+	excludedColumns     map[string]bool
+	autoTimes           map[string]string
+	imports             []string
+	retry               int
+	retryInterval       time.Duration
+	keepGoing           bool
+	verbose             bool
+	quiet               bool
+	logger              Logger
+	postProcess         func(tableName string, src []byte) ([]byte, error)
+	writerFor           func(tableName string) (io.WriteCloser, error)
+}
+
+// stringsFlag is a flag.Value collecting comma-separated values from a flag which may be repeated.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// dataSourceNamesFlag is the flag.Value of -dbc, which may be repeated: the first data source name
+// is Config.DataSourceName and the others Config.DataSourceNames.
+type dataSourceNamesFlag struct {
+	config *Config
+}
+
+func (f dataSourceNamesFlag) String() string {
+	if f.config == nil {
+		return ""
+	}
+	return f.config.DataSourceName
+}
+
+func (f dataSourceNamesFlag) Set(value string) error {
+	if f.config.DataSourceName == "" {
+		f.config.DataSourceName = value
+	} else {
+		f.config.DataSourceNames = append(f.config.DataSourceNames, value)
+	}
+	return nil
 }
 
 func printUsageAndExit(exampleDataSourceName string) {
 	cmd := os.Args[0]
 	_, _ = fmt.Fprintf(os.Stderr, `Usage:
-	%s -o outpath -dbc databaseConnection [-t table1,table2,...] [-tag gorm,json,pg] [-forcecases ID,IDs,HTML]
+	%s (-o outpath | -stdout) -dbc databaseConnection [-config sqlmodel.json] [-t table1,table2,...] [-tag gorm,json,pg,validate] [-forcecases ID,IDs,HTML] [options]
 Example:
 	%s "%s"
+Options:
 `, cmd, cmd, fmt.Sprintf("-o ./ -d %s", exampleDataSourceName))
-	os.Exit(1)
+	flag.PrintDefaults()
+	os.Exit(2)
 }
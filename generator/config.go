@@ -0,0 +1,482 @@
+package generator
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/build/constraint"
+	"go/token"
+	"io"
+	"log"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Config configures the code generation. Each field can be given by the command line flag in its
+// flag tag, or by the key in its json tag in the file given by -config.
+type Config struct {
+	Output         string `json:"output" flag:"o"`
+	Package        string `json:"package" flag:"package"`
+	DataSourceName string `json:"dbc" flag:"dbc"`
+	// DataSourceNames are more data source names of the same driver, generated along with
+	// DataSourceName into subdirectories of the output path named after their databases. -dbc
+	// sets it when repeated.
+	DataSourceNames  []string `json:"dbcs" flag:"dbc"`
+	TLSCA            string   `json:"tlsca" flag:"tlsca"`
+	TLSCert          string   `json:"tlscert" flag:"tlscert"`
+	TLSKey           string   `json:"tlskey" flag:"tlskey"`
+	TLSServerName    string   `json:"tlsservername" flag:"tlsservername"`
+	PerPackage       bool     `json:"perpackage" flag:"perpackage"`
+	Stdout           bool     `json:"stdout" flag:"stdout"`
+	Template         string   `json:"template" flag:"template"`
+	Proto            string   `json:"proto" flag:"proto"`
+	Registry         bool     `json:"registry" flag:"registry"`
+	Manifest         string   `json:"manifest" flag:"manifest"`
+	GitAttributes    bool     `json:"gitattributes" flag:"gitattributes"`
+	GoGenerate       bool     `json:"gogenerate" flag:"gogenerate"`
+	Overwrite        string   `json:"overwrite" flag:"overwrite"`
+	Changed          bool     `json:"changed" flag:"changed"`
+	DryRun           bool     `json:"dryrun" flag:"dryrun"`
+	Tables           []string `json:"tables" flag:"t"`
+	TablesFile       string   `json:"tablesfile" flag:"tablesfile"`
+	MaxTables        int      `json:"maxtables" flag:"maxtables"`
+	Tags             []string `json:"tags" flag:"tag"`
+	ForceCases       []string `json:"forcecases" flag:"forcecases"`
+	TrimPrefixes     []string `json:"trimprefix" flag:"trimprefix"`
+	StructPrefix     string   `json:"structprefix" flag:"structprefix"`
+	StructSuffix     string   `json:"structsuffix" flag:"structsuffix"`
+	Singularize      bool     `json:"singularize" flag:"singularize"`
+	Schemas          []string `json:"schema" flag:"schema"`
+	Embed            string   `json:"embed" flag:"embed"`
+	Views            bool     `json:"views" flag:"views"`
+	Relations        bool     `json:"relations" flag:"relations"`
+	FileCase         string   `json:"filecase" flag:"filecase"`
+	TableNameMode    string   `json:"tablenamemode" flag:"tablenamemode"`
+	NoTableName      []string `json:"notablename" flag:"notablename"`
+	TableNameFunc    string   `json:"tablenamefunc" flag:"tablenamefunc"`
+	FieldOrder       string   `json:"fieldorder" flag:"fieldorder"`
+	JSONType         string   `json:"jsontype" flag:"jsontype"`
+	YearType         string   `json:"yeartype" flag:"yeartype"`
+	DateType         string   `json:"datetype" flag:"datetype"`
+	TimeOnly         bool     `json:"timeonly" flag:"timeonly"`
+	Decimal          bool     `json:"decimal" flag:"decimal"`
+	Nullable         string   `json:"nullable" flag:"nullable"`
+	NullPointer      []string `json:"nullpointer" flag:"nullpointer"`
+	TimeType         string   `json:"timetype" flag:"timetype"`
+	AllPointers      bool     `json:"allpointers" flag:"allpointers"`
+	Float32          bool     `json:"float32" flag:"float32"`
+	BoolColumns      bool     `json:"boolcolumns" flag:"boolcolumns"`
+	NetTypes         bool     `json:"nettypes" flag:"nettypes"`
+	Enums            bool     `json:"enums" flag:"enums"`
+	Header           string   `json:"header" flag:"header"`
+	HeaderReplace    bool     `json:"header-replace" flag:"header-replace"`
+	Marker           string   `json:"marker" flag:"marker"`
+	BuildTags        string   `json:"buildtags" flag:"buildtags"`
+	PreserveNames    bool     `json:"preservenames" flag:"preservenames"`
+	CommentTrim      string   `json:"commenttrim" flag:"commenttrim"`
+	NoComments       bool     `json:"nocomments" flag:"nocomments"`
+	Constructors     bool     `json:"constructors" flag:"constructors"`
+	JSONMethods      bool     `json:"jsonmethods" flag:"jsonmethods"`
+	JSONStringInts   bool     `json:"jsonstringints" flag:"jsonstringints"`
+	OmitEmpty        bool     `json:"omitempty" flag:"omitempty"`
+	DiffMethods      bool     `json:"diffmethods" flag:"diffmethods"`
+	Stringer         bool     `json:"stringer" flag:"stringer"`
+	ORM              string   `json:"orm" flag:"orm"`
+	QuoteIdentifiers bool     `json:"quoteidentifiers" flag:"quoteidentifiers"`
+	SQLHelpers       bool     `json:"sqlhelpers" flag:"sqlhelpers"`
+	FieldMap         bool     `json:"fieldmap" flag:"fieldmap"`
+	EmptyInterface   string   `json:"emptyinterface" flag:"emptyinterface"`
+	OnUnknown        string   `json:"onunknown" flag:"onunknown"`
+	AutoTimes        []string `json:"autotime" flag:"autotime"`
+	MapTypes         []string `json:"maptype" flag:"maptype"`
+	ExcludeColumns   []string `json:"excludecolumns" flag:"excludecolumns"`
+	Imports          []string `json:"imports" flag:"imports"`
+	Retry            int      `json:"retry" flag:"retry"`
+	RetryInterval    string   `json:"retryinterval" flag:"retryinterval"`
+	KeepGoing        bool     `json:"keepgoing" flag:"keepgoing"`
+	Strict           bool     `json:"strict" flag:"strict"`
+	Verbose          bool     `json:"verbose" flag:"v"`
+	Quiet            bool     `json:"quiet" flag:"q"`
+	// Logger receives the progress messages and warnings, defaults to a logger writing to stderr
+	Logger Logger `json:"-"`
+	// PostProcess, if set, is given the source of each file before it is written and returns the
+	// source to write instead. tableName is empty for the single file of Stdout and the files which
+	// are not generated from a table.
+	PostProcess func(tableName string, src []byte) ([]byte, error) `json:"-"`
+	// WriterFor, if set, returns the writer each generated file is written to and closed instead of
+	// the output path. It is called with the table name of the files of tables, and with the file
+	// name, such as sqlmodel_registry.go, for the other files.
+	WriterFor func(tableName string) (io.WriteCloser, error) `json:"-"`
+}
+
+func loadConfigFile(path string) (config Config, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(content, &config); err != nil {
+		err = fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return
+}
+
+// loadTablesFile reads the table names of -tablesfile, one per line. Blank lines and lines starting
+// with # are ignored.
+func loadTablesFile(path string) (tableNames []string, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			tableNames = append(tableNames, line)
+		}
+	}
+	return
+}
+
+// mergeFlags copies the fields of flagConfig whose flags are set on the command line to config.
+func mergeFlags(config *Config, flagConfig *Config) {
+	configType := reflect.TypeOf(*config)
+	flag.Visit(func(f *flag.Flag) {
+		for i := 0; i < configType.NumField(); i++ {
+			if configType.Field(i).Tag.Get("flag") == f.Name {
+				reflect.ValueOf(config).Elem().Field(i).Set(reflect.ValueOf(flagConfig).Elem().Field(i))
+			}
+		}
+	})
+}
+
+// setDataSourceNameFromEnv sets the data source name of config to $SQLMODEL_DSN unless -dbc or the
+// config file gave one, which take precedence.
+func setDataSourceNameFromEnv(config *Config) {
+	if len(config.DataSourceName) == 0 && len(config.DataSourceNames) == 0 {
+		config.DataSourceName = os.Getenv(dataSourceNameEnv)
+	}
+}
+
+func newOptions(driverName string, config Config) (options options, err error) {
+	options.driverName = driverName
+	options.outputPath = config.Output
+	if config.Package != "" && !token.IsIdentifier(config.Package) {
+		err = fmt.Errorf("invalid package name %s", config.Package)
+		return
+	}
+	options.packageName = config.Package
+	options.dataSourceName = config.DataSourceName
+	options.tls = tlsOptions{caFile: config.TLSCA, certFile: config.TLSCert, keyFile: config.TLSKey, serverName: config.TLSServerName}
+	if config.Stdout {
+		options.stdout = &sourceFile{}
+	}
+	if config.Registry {
+		options.registry = &registry{}
+	}
+	if config.Manifest != "" {
+		options.manifest = &manifest{path: config.Manifest}
+	}
+	options.template = defaultTemplate
+	if config.Template != "" {
+		if config.Stdout {
+			err = errors.New("-template renders whole files, it cannot be combined with -stdout")
+			return
+		}
+		if options.template, err = parseTemplateFile(config.Template); err != nil {
+			return
+		}
+	}
+	if config.Proto != "" {
+		options.proto = &protoFile{path: config.Proto}
+	}
+	options.dryRun = config.DryRun
+	if config.PerPackage && (config.Stdout || config.Embed != "" || config.Relations) {
+		// embedded structs and relations refer to types of other packages
+		err = errors.New("-perpackage cannot be combined with -stdout, -embed or -relations")
+		return
+	}
+	options.perPackage = config.PerPackage
+	options.changed = config.Changed
+	options.gitAttributes = config.GitAttributes
+	options.tableNames = config.Tables
+	if config.TablesFile != "" {
+		var tableNames []string
+		if tableNames, err = loadTablesFile(config.TablesFile); err != nil {
+			return
+		}
+		for _, tableName := range tableNames {
+			if !containsString(options.tableNames, tableName) {
+				options.tableNames = append(options.tableNames, tableName)
+			}
+		}
+	}
+	if config.MaxTables < 0 {
+		err = fmt.Errorf("invalid max tables %d", config.MaxTables)
+		return
+	}
+	options.maxTables = config.MaxTables
+	options.tags = sortTags(config.Tags)
+	options.forceCases = config.ForceCases
+	options.trimPrefixes = config.TrimPrefixes
+	options.schemas = config.Schemas
+	if len(config.Embed) != 0 {
+		if options.embedded, err = parseEmbeddedStruct(config.Embed); err != nil {
+			return
+		}
+	}
+	options.views = config.Views
+	options.relations = config.Relations
+	options.singularize = config.Singularize
+	options.structPrefix = config.StructPrefix
+	options.structSuffix = config.StructSuffix
+	switch config.JSONType {
+	case "":
+		options.jsonType = "string"
+	case "raw", "string":
+		options.jsonType = config.JSONType
+	default:
+		err = fmt.Errorf("invalid json type %s", config.JSONType)
+		return
+	}
+	switch config.YearType {
+	case "":
+		options.yearType = "int16"
+	case "int16", "string":
+		options.yearType = config.YearType
+	default:
+		err = fmt.Errorf("invalid year type %s", config.YearType)
+		return
+	}
+	if options.timeType, err = parseTimeType(config.TimeType); err != nil {
+		return
+	}
+	options.timeOnly = config.TimeOnly
+	options.decimal = config.Decimal
+	switch config.Nullable {
+	case "":
+		options.nullable = "pointer"
+		if len(config.NullPointer) != 0 {
+			options.nullable = "sql"
+		}
+	case "pointer", "sql":
+		options.nullable = config.Nullable
+	default:
+		err = fmt.Errorf("invalid nullable %s", config.Nullable)
+		return
+	}
+	for _, family := range config.NullPointer {
+		switch family {
+		case "int", "uint", "float", "string", "bool", "time":
+		default:
+			err = fmt.Errorf("invalid null pointer type %s", family)
+			return
+		}
+	}
+	if len(config.NullPointer) != 0 && options.nullable != "sql" {
+		err = errors.New("-nullpointer chooses the types left as pointers by -nullable sql, it cannot be combined with -nullable pointer")
+		return
+	}
+	options.nullPointer = config.NullPointer
+	switch config.DateType {
+	case "":
+		options.dateType = "time"
+	case "time", "civil", "string":
+		options.dateType = config.DateType
+	default:
+		err = fmt.Errorf("invalid date type %s", config.DateType)
+		return
+	}
+	options.boolColumns = config.BoolColumns
+	options.float32 = config.Float32
+	options.allPointers = config.AllPointers
+	options.netTypes = config.NetTypes
+	options.enums = config.Enums
+	options.preserveNames = config.PreserveNames
+	if len(config.CommentTrim) != 0 {
+		if options.commentTrim, err = regexp.Compile(config.CommentTrim); err != nil {
+			err = fmt.Errorf("invalid comment trim %s: %w", config.CommentTrim, err)
+			return
+		}
+	}
+	options.noComments = config.NoComments
+	options.stringer = config.Stringer
+	switch config.ORM {
+	case "":
+	case "sqlingo":
+		options.orm = config.ORM
+	default:
+		err = fmt.Errorf("invalid orm %s", config.ORM)
+		return
+	}
+	options.jsonMethods = config.JSONMethods
+	options.jsonStringInts = config.JSONStringInts
+	options.omitEmpty = config.OmitEmpty
+	options.diffMethods = config.DiffMethods
+	options.constructors = config.Constructors
+	options.sqlHelpers = config.SQLHelpers
+	options.fieldMap = config.FieldMap
+	options.quoteIdentifiers = config.QuoteIdentifiers
+	switch config.FileCase {
+	case "":
+		options.fileCase = "table"
+	case "snake", "struct", "table":
+		options.fileCase = config.FileCase
+	default:
+		err = fmt.Errorf("invalid file case %s", config.FileCase)
+		return
+	}
+	switch config.Overwrite {
+	case "":
+		options.overwrite = "prompt"
+	case "always", "never", "prompt":
+		options.overwrite = config.Overwrite
+	default:
+		err = fmt.Errorf("invalid overwrite %s", config.Overwrite)
+		return
+	}
+	switch config.TableNameMode {
+	case "":
+		options.tableNameMode = "value"
+	case "value", "pointer", "none":
+		options.tableNameMode = config.TableNameMode
+	default:
+		err = fmt.Errorf("invalid table name mode %s", config.TableNameMode)
+		return
+	}
+	for _, pattern := range config.NoTableName {
+		if _, err = path.Match(pattern, ""); err != nil {
+			err = fmt.Errorf("invalid table name pattern %s: %w", pattern, err)
+			return
+		}
+	}
+	options.noTableName = config.NoTableName
+	switch {
+	case config.TableNameFunc == "":
+		options.tableNameFunc = "TableName"
+	case token.IsIdentifier(config.TableNameFunc):
+		options.tableNameFunc = config.TableNameFunc
+	default:
+		err = fmt.Errorf("invalid table name func %s", config.TableNameFunc)
+		return
+	}
+	switch config.FieldOrder {
+	case "":
+		options.fieldOrder = "schema"
+	case "schema", "alpha":
+		options.fieldOrder = config.FieldOrder
+	default:
+		err = fmt.Errorf("invalid field order %s", config.FieldOrder)
+		return
+	}
+	switch config.EmptyInterface {
+	case "":
+		options.emptyInterface = "any"
+	case "any", "interface{}":
+		options.emptyInterface = config.EmptyInterface
+	default:
+		err = fmt.Errorf("invalid empty interface %s", config.EmptyInterface)
+		return
+	}
+	switch config.OnUnknown {
+	case "":
+		options.onUnknown = "error"
+	case "error", "skip", "any":
+		options.onUnknown = config.OnUnknown
+	default:
+		err = fmt.Errorf("invalid unknown type policy %s", config.OnUnknown)
+		return
+	}
+	if len(config.Header) != 0 {
+		var content []byte
+		if content, err = os.ReadFile(config.Header); err != nil {
+			return
+		}
+		options.header = string(content)
+		if !strings.HasSuffix(options.header, "\n") {
+			options.header += "\n"
+		}
+		options.headerReplace = config.HeaderReplace
+	}
+	options.marker = defaultMarker
+	if config.Marker != "" {
+		if strings.Contains(config.Marker, "\n") {
+			err = fmt.Errorf("invalid marker %q, it must be a single line", config.Marker)
+			return
+		}
+		options.marker = config.Marker
+		if !strings.HasPrefix(options.marker, "//") {
+			options.marker = "// " + options.marker
+		}
+	}
+	if config.GoGenerate {
+		if config.Stdout {
+			err = errors.New("-gogenerate writes a file of the output path, it cannot be combined with -stdout")
+			return
+		}
+		options.goGenerate = &config
+	}
+	if len(config.BuildTags) != 0 {
+		var expr constraint.Expr
+		if expr, err = constraint.Parse("//go:build " + config.BuildTags); err != nil {
+			err = fmt.Errorf("invalid build tags %s: %w", config.BuildTags, err)
+			return
+		}
+		options.buildConstraints = append(options.buildConstraints, "//go:build "+expr.String())
+		var plusBuildLines []string
+		if plusBuildLines, err = constraint.PlusBuildLines(expr); err != nil {
+			err = fmt.Errorf("invalid build tags %s: %w", config.BuildTags, err)
+			return
+		}
+		options.buildConstraints = append(options.buildConstraints, plusBuildLines...)
+	}
+	options.typeOverrides = make(map[string]string)
+	for _, mapType := range config.MapTypes {
+		column, goType, ok := strings.Cut(mapType, "=")
+		if !ok || !strings.Contains(column, ".") || goType == "" {
+			err = fmt.Errorf("invalid type mapping %s", mapType)
+			return
+		}
+		options.typeOverrides[strings.ToLower(column)] = goType
+	}
+	options.excludedColumns = make(map[string]bool)
+	for _, column := range config.ExcludeColumns {
+		tableName, columnName, ok := strings.Cut(column, ".")
+		if !ok || tableName == "" || columnName == "" {
+			err = fmt.Errorf("invalid excluded column %s", column)
+			return
+		}
+		options.excludedColumns[strings.ToLower(column)] = true
+	}
+	options.autoTimes = make(map[string]string)
+	for _, autoTime := range config.AutoTimes {
+		column, kind, _ := strings.Cut(autoTime, ":")
+		if column == "" || (kind != "create" && kind != "update") {
+			err = fmt.Errorf("invalid auto time %s", autoTime)
+			return
+		}
+		options.autoTimes[strings.ToLower(column)] = kind
+	}
+	options.imports = config.Imports
+	options.retry = config.Retry
+	options.retryInterval = time.Second
+	if len(config.RetryInterval) != 0 {
+		if options.retryInterval, err = time.ParseDuration(config.RetryInterval); err != nil {
+			err = fmt.Errorf("invalid retry interval %s: %w", config.RetryInterval, err)
+			return
+		}
+	}
+	options.keepGoing = config.KeepGoing
+	options.verbose = config.Verbose
+	options.quiet = config.Quiet
+	options.postProcess = config.PostProcess
+	options.writerFor = config.WriterFor
+	options.logger = config.Logger
+	if options.logger == nil {
+		options.logger = log.New(os.Stderr, "", 0)
+	}
+	return
+}
@@ -0,0 +1,80 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sqlmodel.json")
+	content := `{"output": "models", "dbc": "app.db", "tables": ["users", "posts"], "tags": ["json", "gorm"], "forcecases": ["ID"], "trimprefix": ["t_"], "maxtables": 10}`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Output:         "models",
+		DataSourceName: "app.db",
+		Tables:         []string{"users", "posts"},
+		Tags:           []string{"json", "gorm"},
+		ForceCases:     []string{"ID"},
+		TrimPrefixes:   []string{"t_"},
+		MaxTables:      10,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("loadConfigFile() = %+v, want %+v", config, want)
+	}
+
+	if err = os.WriteFile(path, []byte(`{"output": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = loadConfigFile(path); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("loadConfigFile() = %v, want an error naming the file", err)
+	}
+}
+
+func TestLoadTablesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tables.txt")
+	content := "# shop tables\nusers\n\n  orders  \n\t\n# posts\nproducts\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tableNames, err := loadTablesFile(path)
+	if want := []string{"users", "orders", "products"}; err != nil || !reflect.DeepEqual(tableNames, want) {
+		t.Errorf("loadTablesFile() = %q, %v, want %q", tableNames, err, want)
+	}
+
+	options, err := newOptions("sqlite3", Config{Tables: []string{"users", "reviews"}, TablesFile: path})
+	if want := []string{"users", "reviews", "orders", "products"}; err != nil || !reflect.DeepEqual(options.tableNames, want) {
+		t.Errorf("newOptions() tables = %q, %v, want %q", options.tableNames, err, want)
+	}
+
+	if _, err = loadTablesFile(filepath.Join(t.TempDir(), "missing.txt")); !os.IsNotExist(err) {
+		t.Errorf("loadTablesFile() = %v, want a missing file", err)
+	}
+}
+
+func TestSetDataSourceNameFromEnv(t *testing.T) {
+	t.Setenv(dataSourceNameEnv, "env.db")
+	tests := []struct {
+		config Config
+		want   Config
+	}{
+		{Config{}, Config{DataSourceName: "env.db"}},
+		{Config{DataSourceName: "flag.db"}, Config{DataSourceName: "flag.db"}},
+		{Config{DataSourceNames: []string{"shop.db", "blog.db"}}, Config{DataSourceNames: []string{"shop.db", "blog.db"}}},
+	}
+	for _, test := range tests {
+		config := test.config
+		setDataSourceNameFromEnv(&config)
+		if !reflect.DeepEqual(config, test.want) {
+			t.Errorf("setDataSourceNameFromEnv(%+v) = %+v, want %+v", test.config, config, test.want)
+		}
+	}
+}
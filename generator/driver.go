@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// inferDriver infers the driver from the scheme of a URL data source name, or the extension of a
+// SQLite file. URL data source names of MySQL are converted to the format of its driver, other
+// MySQL data source names need the driver to be given explicitly.
+func inferDriver(dataSourceName string) (driverName string, driverDataSourceName string, err error) {
+	scheme, _, ok := strings.Cut(dataSourceName, "://")
+	if !ok {
+		if strings.HasPrefix(dataSourceName, "file:") {
+			return "sqlite3", dataSourceName, nil
+		}
+		switch filepath.Ext(dataSourceName) {
+		case ".db", ".sqlite", ".sqlite3":
+			return "sqlite3", dataSourceName, nil
+		}
+		return "", "", fmt.Errorf("cannot infer the driver of %s, give it explicitly", redactDataSourceName(dataSourceName))
+	}
+	switch strings.ToLower(scheme) {
+	case "postgres", "postgresql":
+		return "postgres", dataSourceName, nil
+	case "mysql":
+		u, err := url.Parse(dataSourceName)
+		if err != nil {
+			return "", "", err
+		}
+		// the MySQL driver doesn't decode the user and password, so they are given as they are
+		var userInfo string
+		if u.User != nil {
+			userInfo = u.User.Username()
+			if password, ok := u.User.Password(); ok {
+				userInfo += ":" + password
+			}
+			userInfo += "@"
+		}
+		dataSourceName = fmt.Sprintf("%stcp(%s)%s", userInfo, u.Host, u.Path)
+		if u.RawQuery != "" {
+			dataSourceName += "?" + u.RawQuery
+		}
+		return "mysql", dataSourceName, nil
+	case "sqlserver":
+		return "sqlserver", dataSourceName, nil
+	case "clickhouse", "tcp":
+		return "clickhouse", dataSourceName, nil
+	}
+	return "", "", fmt.Errorf("cannot infer the driver of scheme %s, give it explicitly", scheme)
+}
+
+// passwordParameterRegexp matches the password of key=value data source names of Postgres and SQL
+// Server, or of URL query parameters.
+var passwordParameterRegexp = regexp.MustCompile(`(?i)\b(password|pwd)=[^ ;&]*`)
+
+// redactDataSourceName hides the password of a data source name in messages.
+func redactDataSourceName(dataSourceName string) string {
+	dataSourceName = passwordParameterRegexp.ReplaceAllString(dataSourceName, "${1}=xxxxx")
+	if u, err := url.Parse(dataSourceName); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	// user:password@tcp(host)/db format of MySQL
+	if at := strings.LastIndex(dataSourceName, "@"); at >= 0 {
+		if colon := strings.Index(dataSourceName[:at], ":"); colon >= 0 {
+			return dataSourceName[:colon+1] + "xxxxx" + dataSourceName[at:]
+		}
+	}
+	return dataSourceName
+}
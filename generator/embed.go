@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// embeddedStruct is a struct given by -embed, which replaces its columns in the models of the tables
+// having all of them with the same types.
+type embeddedStruct struct {
+	name    string
+	columns []string
+	// goTypes holds the types of the columns in the first table the struct is embedded in
+	goTypes map[string]string
+	fields  []FieldInfo
+}
+
+func parseEmbeddedStruct(s string) (*embeddedStruct, error) {
+	name, columns, ok := strings.Cut(s, ":")
+	if !ok || name == "" || columns == "" {
+		return nil, fmt.Errorf("invalid embedded struct %s", s)
+	}
+	return &embeddedStruct{name: name, columns: strings.Split(columns, ",")}, nil
+}
+
+// embeds reports whether the struct is embedded in the model of the table with the given column types.
+func (e *embeddedStruct) embeds(tableName string, goTypes map[string]string, logger Logger) bool {
+	for _, column := range e.columns {
+		if _, ok := goTypes[column]; !ok {
+			return false
+		}
+	}
+	if e.goTypes == nil {
+		return true
+	}
+	for _, column := range e.columns {
+		if goTypes[column] != e.goTypes[column] {
+			logger.Printf("warning: %s.%s is %s instead of %s, %s is not embedded",
+				tableName, column, goTypes[column], e.goTypes[column], e.name)
+			return false
+		}
+	}
+	return true
+}
+
+func writeEmbeddedStruct(dbName string, e *embeddedStruct, options options) error {
+	var buf bytes.Buffer
+	// the fields are declared by the embedded struct itself
+	data := TemplateData{StructName: e.name}
+	var imports []string
+	for _, fieldInfo := range e.fields {
+		fieldInfo.Embedded = false
+		data.Fields = append(data.Fields, fieldInfo)
+		imports = appendImports(imports, fieldInfo.Imports...)
+	}
+	if err := options.template.ExecuteTemplate(&buf, "struct", data); err != nil {
+		return err
+	}
+	return writeSource(dbName, "", getFileName(convertToSnakeCase(e.name), e.name, options.fileCase), imports, buf.Bytes(), options)
+}
@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEmbed(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, created_at DATETIME, title TEXT)",
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, created_at DATETIME, name TEXT)",
+		"CREATE TABLE tags (id TEXT PRIMARY KEY, created_at DATETIME)",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{Embed: "Base:id,created_at", Stringer: true, SQLHelpers: true})
+
+	base := files["base.go"]
+	if !strings.Contains(base, "type Base struct {") || !strings.Contains(base, "\tId int64") || !strings.Contains(base, "\tCreatedAt *time.Time") {
+		t.Errorf("base.go = %q, want the embedded columns", base)
+	}
+	for _, tableName := range []string{"posts", "users"} {
+		file := files[tableName+".go"]
+		if !strings.Contains(file, "\tBase\n") || strings.Contains(file, "\tId int64") {
+			t.Errorf("%s.go = %q, want Base embedded instead of its columns", tableName, file)
+		}
+		// the tables after the first one embedding Base have its promoted fields too
+		for _, want := range []string{`b.WriteString("Posts{Id: ")`, `"id", "created_at"`, "&m.Id, &m.CreatedAt"} {
+			if tableName == "users" {
+				want = strings.Replace(want, "Posts", "Users", 1)
+			}
+			if !strings.Contains(file, want) {
+				t.Errorf("%s.go = %q, want %s", tableName, file, want)
+			}
+		}
+	}
+	// the types of tags differ from the ones of Base, so its columns are inlined
+	if tags := files["tags.go"]; strings.Contains(tags, "\tBase\n") || !strings.Contains(tags, "\tId *string") {
+		t.Errorf("tags.go = %q, want the columns inlined", tags)
+	}
+}
+
+func TestEmbedMethods(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL, total REAL NOT NULL)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL, updated_at DATETIME)",
+	)
+	tests := []Config{
+		{Embed: "Base:id,created_at", Constructors: true},
+		{Embed: "Base:id,created_at", JSONMethods: true},
+		{Embed: "Base:id,created_at", DiffMethods: true},
+		{Embed: "Base:id,created_at", Constructors: true, JSONMethods: true, DiffMethods: true, Stringer: true, SQLHelpers: true},
+	}
+	for _, config := range tests {
+		files := generateTestFiles(t, dataSourceName, config)
+		if !strings.Contains(files["orders.go"], "\tBase\n") {
+			t.Errorf("%+v: orders.go = %q, want Base embedded", config, files["orders.go"])
+		}
+		typeCheck(t, files["base.go"], files["orders.go"], files["posts.go"])
+	}
+}
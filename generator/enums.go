@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// enumTypeDef is a string type generated with -enums for the values of an enum or set column.
+type enumTypeDef struct {
+	name   string
+	values []string
+}
+
+// writeEnumType writes the type with a constant per value, and Valid and IsValid methods checking
+// a value is one of them. The file needs to import fmt.
+func writeEnumType(buf *bytes.Buffer, enumType enumTypeDef, options options) {
+	buf.WriteString(fmt.Sprintf("type %s string\n\n", enumType.name))
+	var constants []string
+	buf.WriteString("const (\n")
+	for _, value := range enumType.values {
+		constant := enumType.name + convertToExportedIdentifier(value, options.forceCases)
+		constants = append(constants, constant)
+		buf.WriteString(fmt.Sprintf("\t%s %s = %q\n", constant, enumType.name, value))
+	}
+	buf.WriteString(")\n\n")
+
+	buf.WriteString(fmt.Sprintf("// Valid reports whether s is one of the %s constants.\n", enumType.name))
+	buf.WriteString(fmt.Sprintf("func (s %s) Valid() bool {\n", enumType.name))
+	if len(constants) != 0 {
+		buf.WriteString("\tswitch s {\n")
+		buf.WriteString(fmt.Sprintf("\tcase %s:\n", strings.Join(constants, ", ")))
+		buf.WriteString("\t\treturn true\n")
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\treturn false\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// IsValid returns an error if s is not one of the %s constants.\n", enumType.name))
+	buf.WriteString(fmt.Sprintf("func (s %s) IsValid() error {\n", enumType.name))
+	buf.WriteString("\tif !s.Valid() {\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s %%q\", string(s))\n", enumType.name))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n\n")
+}
@@ -0,0 +1,27 @@
+package generator
+
+import "testing"
+
+func TestEnumValid(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"users": {
+		{Name: "id", Type: "int", PrimaryKeyOrdinal: 1},
+		{Name: "status", Type: "enum", EnumValues: []string{"active", "banned"}},
+	}}}
+	output := t.TempDir()
+	if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, Package: "main", Enums: true, Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	files := readTestFiles(t, output)
+	files["main.go"] = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(UsersStatusActive.Valid(), UsersStatusBanned.IsValid())
+	fmt.Println(UsersStatus("deleted").Valid(), UsersStatus("deleted").IsValid())
+}
+`
+	if output, want := runGoProgram(t, files), "true <nil>\nfalse invalid UsersStatus \"deleted\"\n"; output != want {
+		t.Errorf("the enum methods printed %q, want %q", output, want)
+	}
+}
@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrUnsupportedDriver is returned for a driver without a built-in SchemaFetcher.
+var ErrUnsupportedDriver = errors.New("unsupported driver")
+
+// ErrNoTables is returned when the database has no tables to generate.
+var ErrNoTables = errors.New("no tables found")
+
+// TableError is the error of generating a table.
+type TableError struct {
+	TableName string
+	Err       error
+}
+
+func (e TableError) Error() string {
+	return e.TableName + ": " + e.Err.Error()
+}
+
+func (e TableError) Unwrap() error {
+	return e.Err
+}
+
+// DataSourceError is the error of generating the database of a data source name, whose password is
+// redacted.
+type DataSourceError struct {
+	DataSourceName string
+	Err            error
+}
+
+func (e DataSourceError) Error() string {
+	return e.DataSourceName + ": " + e.Err.Error()
+}
+
+func (e DataSourceError) Unwrap() error {
+	return e.Err
+}
+
+// DataSourceErrors is returned when the databases of some data source names failed to generate.
+type DataSourceErrors []DataSourceError
+
+func (e DataSourceErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "failed to generate databases: " + strings.Join(messages, "; ")
+}
+
+// TableErrors is returned with -keepgoing when some tables failed to generate.
+type TableErrors []TableError
+
+func (e TableErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return "failed to generate tables: " + strings.Join(messages, "; ")
+}
+
+// ExitCode returns the exit status of the commands for the error of Generate: 0 without error, 2
+// for ErrUnsupportedDriver, which is an error of usage, and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, ErrUnsupportedDriver) {
+		return 2
+	}
+	var dataSourceErrors DataSourceErrors
+	if errors.As(err, &dataSourceErrors) {
+		for _, dataSourceError := range dataSourceErrors {
+			if errors.Is(dataSourceError, ErrUnsupportedDriver) {
+				return 2
+			}
+		}
+	}
+	return 1
+}
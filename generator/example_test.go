@@ -0,0 +1,61 @@
+package generator_test
+
+import (
+	"go/format"
+	"log"
+
+	"github.com/Ficoto/sqlmodel/generator"
+)
+
+// staticSchemaFetcher is a SchemaFetcher of a fixed schema, such as one read from a database
+// without a built-in driver.
+type staticSchemaFetcher struct{}
+
+func (staticSchemaFetcher) GetDatabaseName() (string, error) {
+	return "shop", nil
+}
+
+func (staticSchemaFetcher) GetTableNames() ([]string, error) {
+	return []string{"orders"}, nil
+}
+
+func (staticSchemaFetcher) GetViewNames() ([]string, error) {
+	return nil, nil
+}
+
+func (staticSchemaFetcher) GetFieldDescriptors(tableName string) ([]generator.FieldDescriptor, error) {
+	return []generator.FieldDescriptor{
+		{Name: "id", Type: "bigint", PrimaryKeyOrdinal: 1, IsAutoIncrement: true},
+		{Name: "note", Type: "varchar", Size: 200, AllowNull: true},
+	}, nil
+}
+
+func (staticSchemaFetcher) QuoteIdentifier(identifier string) string {
+	return `"` + identifier + `"`
+}
+
+func ExampleGenerateWithFetcher() {
+	err := generator.GenerateWithFetcher(staticSchemaFetcher{}, generator.Config{
+		Stdout: true,
+		Tags:   []string{"json"},
+		Quiet:  true,
+		PostProcess: func(tableName string, src []byte) ([]byte, error) {
+			return format.Source(src)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// // Code generated by sqlmodel; DO NOT EDIT.
+	// package shop
+	//
+	// type Orders struct {
+	// 	Id   int64   `json:"id"`
+	// 	Note *string `json:"note"`
+	// }
+	//
+	// func (m Orders) TableName() string {
+	// 	return "orders"
+	// }
+}
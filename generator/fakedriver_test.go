@@ -0,0 +1,153 @@
+package generator
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeResult is the result of the queries of a fakeDatabase containing a string.
+type fakeResult struct {
+	contains string
+	columns  []string
+	rows     [][]driver.Value
+}
+
+// fakeQuery is a query run on a fakeDatabase.
+type fakeQuery struct {
+	query string
+	args  []driver.Value
+}
+
+// fakeDatabase answers the queries of the schema fetchers with fixed results, for the databases
+// without a server in the tests.
+type fakeDatabase struct {
+	results []fakeResult
+	mu      sync.Mutex
+	queries []fakeQuery
+}
+
+var (
+	fakeDatabasesMu sync.Mutex
+	fakeDatabases   = make(map[string]*fakeDatabase)
+)
+
+func init() {
+	sql.Register("fake", fakeDriver{})
+}
+
+// newFakeDB opens a fakeDatabase answering with results, the first result whose string the query
+// contains, or no rows.
+func newFakeDB(t *testing.T, results ...fakeResult) (*sql.DB, *fakeDatabase) {
+	t.Helper()
+	database := &fakeDatabase{results: results}
+	fakeDatabasesMu.Lock()
+	fakeDatabases[t.Name()] = database
+	fakeDatabasesMu.Unlock()
+	db, err := sql.Open("fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		fakeDatabasesMu.Lock()
+		delete(fakeDatabases, t.Name())
+		fakeDatabasesMu.Unlock()
+	})
+	return db, database
+}
+
+// query returns the query run on the database containing s.
+func (d *fakeDatabase) query(s string) (fakeQuery, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, query := range d.queries {
+		if strings.Contains(query.query, s) {
+			return query, true
+		}
+	}
+	return fakeQuery{}, false
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	fakeDatabasesMu.Lock()
+	defer fakeDatabasesMu.Unlock()
+	database, ok := fakeDatabases[name]
+	if !ok {
+		return nil, errors.New("no fake database " + name)
+	}
+	return fakeConn{database}, nil
+}
+
+type fakeConn struct {
+	database *fakeDatabase
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return fakeStmt{c.database, query}, nil
+}
+
+func (fakeConn) Close() error {
+	return nil
+}
+
+func (fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("no transactions")
+}
+
+type fakeStmt struct {
+	database *fakeDatabase
+	query    string
+}
+
+func (fakeStmt) Close() error {
+	return nil
+}
+
+func (fakeStmt) NumInput() int {
+	return -1
+}
+
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("no statements")
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.database.mu.Lock()
+	defer s.database.mu.Unlock()
+	s.database.queries = append(s.database.queries, fakeQuery{s.query, args})
+	for _, result := range s.database.results {
+		if strings.Contains(s.query, result.contains) {
+			return &fakeRows{columns: result.columns, rows: result.rows}, nil
+		}
+	}
+	return &fakeRows{columns: []string{"value"}}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
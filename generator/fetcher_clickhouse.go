@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+type clickHouseSchemaFetcher struct {
+	db queryer
+}
+
+func (c clickHouseSchemaFetcher) GetDatabaseName() (dbName string, err error) {
+	row := c.db.QueryRow("SELECT currentDatabase()")
+	err = row.Scan(&dbName)
+	return
+}
+
+func (c clickHouseSchemaFetcher) GetTableNames() (tableNames []string, err error) {
+	return c.queryNames("SELECT name FROM system.tables WHERE database = currentDatabase() AND engine NOT IN ('View', 'MaterializedView')")
+}
+
+func (c clickHouseSchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	return c.queryNames("SELECT name FROM system.tables WHERE database = currentDatabase() AND engine IN ('View', 'MaterializedView')")
+}
+
+func (c clickHouseSchemaFetcher) queryNames(query string) (names []string, err error) {
+	rows, err := c.db.Query(query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return
+		}
+		names = append(names, name)
+	}
+	return
+}
+
+func (c clickHouseSchemaFetcher) GetFieldDescriptors(tableName string) (result []FieldDescriptor, err error) {
+	var primaryKey string
+	row := c.db.QueryRow("SELECT primary_key FROM system.tables WHERE database = currentDatabase() AND name = ?", tableName)
+	if err = row.Scan(&primaryKey); err != nil {
+		return
+	}
+	primaryKeyOrdinals := make(map[string]int)
+	for i, column := range strings.Split(primaryKey, ",") {
+		primaryKeyOrdinals[strings.TrimSpace(column)] = i + 1
+	}
+
+	rows, err := c.db.Query("SELECT name, type, comment, default_kind, default_expression FROM system.columns WHERE database = currentDatabase() AND table = ? ORDER BY position", tableName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fieldDescriptor FieldDescriptor
+		var columnType, defaultKind, defaultExpression string
+		if err = rows.Scan(&fieldDescriptor.Name, &columnType, &fieldDescriptor.Comment, &defaultKind, &defaultExpression); err != nil {
+			return
+		}
+		if defaultKind == "DEFAULT" {
+			// MATERIALIZED and ALIAS columns are computed, not defaults
+			fieldDescriptor.DefaultValue = &defaultExpression
+		}
+		fieldDescriptor.Type, fieldDescriptor.Size, fieldDescriptor.Unsigned, fieldDescriptor.AllowNull = parseClickHouseType(columnType)
+		fieldDescriptor.PrimaryKeyOrdinal = primaryKeyOrdinals[fieldDescriptor.Name]
+		result = append(result, fieldDescriptor)
+	}
+	return
+}
+
+func (c clickHouseSchemaFetcher) QuoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func newClickHouseSchemaFetcher(db *sql.DB, options options) SchemaFetcher {
+	return clickHouseSchemaFetcher{db: newQueryer(db, options)}
+}
+
+// parseClickHouseType splits a column type such as LowCardinality(Nullable(FixedString(16))) into
+// its base type and size. UInt types are reported as the Int type of the same width.
+func parseClickHouseType(columnType string) (fieldType string, size int, unsigned bool, nullable bool) {
+	fieldType = columnType
+	for {
+		if inner, ok := unwrapClickHouseType(fieldType, "LowCardinality"); ok {
+			fieldType = inner
+		} else if inner, ok := unwrapClickHouseType(fieldType, "Nullable"); ok {
+			fieldType = inner
+			nullable = true
+		} else {
+			break
+		}
+	}
+	if i := strings.Index(fieldType, "("); i >= 0 && strings.HasSuffix(fieldType, ")") {
+		args := strings.Split(fieldType[i+1:len(fieldType)-1], ",")
+		size, _ = strconv.Atoi(strings.TrimSpace(args[0]))
+		fieldType = fieldType[:i]
+	}
+	if strings.HasPrefix(fieldType, "UInt") {
+		fieldType = strings.TrimPrefix(fieldType, "U")
+		unsigned = true
+	}
+	return
+}
+
+func unwrapClickHouseType(columnType, wrapper string) (string, bool) {
+	if strings.HasPrefix(columnType, wrapper+"(") && strings.HasSuffix(columnType, ")") {
+		return columnType[len(wrapper)+1 : len(columnType)-1], true
+	}
+	return columnType, false
+}
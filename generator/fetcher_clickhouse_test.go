@@ -0,0 +1,35 @@
+package generator
+
+import "testing"
+
+func TestParseClickHouseType(t *testing.T) {
+	tests := []struct {
+		columnType string
+		fieldType  string
+		size       int
+		unsigned   bool
+		nullable   bool
+		goType     string
+	}{
+		{"UInt64", "Int64", 0, true, false, "uint64"},
+		{"UInt8", "Int8", 0, true, false, "uint8"},
+		{"Int32", "Int32", 0, false, false, "int32"},
+		{"String", "String", 0, false, false, "string"},
+		{"DateTime", "DateTime", 0, false, false, "time.Time"},
+		{"Nullable(String)", "String", 0, false, true, "*string"},
+		{"LowCardinality(String)", "String", 0, false, false, "string"},
+		{"LowCardinality(Nullable(FixedString(16)))", "FixedString", 16, false, true, "*string"},
+		{"Nullable(UInt32)", "Int32", 0, true, true, "*uint32"},
+		{"Decimal(18, 4)", "Decimal", 18, false, false, "float64"},
+	}
+	for _, test := range tests {
+		fieldType, size, unsigned, nullable := parseClickHouseType(test.columnType)
+		if fieldType != test.fieldType || size != test.size || unsigned != test.unsigned || nullable != test.nullable {
+			t.Errorf("parseClickHouseType(%s) = %s, %d, %v, %v, want %s, %d, %v, %v", test.columnType, fieldType, size, unsigned, nullable, test.fieldType, test.size, test.unsigned, test.nullable)
+		}
+		fieldDescriptor := FieldDescriptor{Name: "value", Type: fieldType, Size: size, Unsigned: unsigned, AllowNull: nullable}
+		if goType := testGoType(t, "clickhouse", Config{}, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(%s) = %s, want %s", test.columnType, goType, test.goType)
+		}
+	}
+}
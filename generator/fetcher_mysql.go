@@ -4,10 +4,11 @@ import (
 	"database/sql"
 	"regexp"
 	"strconv"
+	"strings"
 )
 
 type mysqlSchemaFetcher struct {
-	db *sql.DB
+	db queryer
 }
 
 func (m mysqlSchemaFetcher) GetDatabaseName() (dbName string, err error) {
@@ -17,15 +18,23 @@ func (m mysqlSchemaFetcher) GetDatabaseName() (dbName string, err error) {
 }
 
 func (m mysqlSchemaFetcher) GetTableNames() (tableNames []string, err error) {
-	rows, err := m.db.Query("SHOW TABLES")
+	return m.getTableNames("BASE TABLE")
+}
+
+func (m mysqlSchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	return m.getTableNames("VIEW")
+}
+
+func (m mysqlSchemaFetcher) getTableNames(tableType string) (tableNames []string, err error) {
+	rows, err := m.db.Query("SHOW FULL TABLES WHERE Table_type = '" + tableType + "'")
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 
 	for rows.Next() {
-		var name string
-		err = rows.Scan(&name)
+		var name, tableType string
+		err = rows.Scan(&name, &tableType)
 		if err != nil {
 			return
 		}
@@ -34,13 +43,19 @@ func (m mysqlSchemaFetcher) GetTableNames() (tableNames []string, err error) {
 	return
 }
 
-func (m mysqlSchemaFetcher) GetFieldDescriptors(tableName string) ([]fieldDescriptor, error) {
-	rows, err := m.db.Query("SHOW FULL COLUMNS FROM `" + tableName + "`")
+func (m mysqlSchemaFetcher) GetFieldDescriptors(tableName string) ([]FieldDescriptor, error) {
+	primaryKeyOrdinals, err := queryPrimaryKeyOrdinals(m.db, "SELECT COLUMN_NAME, ORDINAL_POSITION FROM information_schema.KEY_COLUMN_USAGE WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'", tableName)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []fieldDescriptor
+	rows, err := m.db.Query("SHOW FULL COLUMNS FROM " + m.QuoteIdentifier(tableName))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []FieldDescriptor
 	for rows.Next() {
 		columns, err := rows.Columns()
 		if err != nil {
@@ -63,35 +78,120 @@ func (m mysqlSchemaFetcher) GetFieldDescriptors(tableName string) ([]fieldDescri
 			}
 		}
 
-		r, _ := regexp.Compile("([a-z]+)(\\(([0-9]+)\\))?( ([a-z]+))?")
-		submatches := r.FindStringSubmatch(row["Type"])
+		fieldType, fieldSize, scale, unsigned := parseMySQLType(row["Type"])
+		var precision int
+		if fieldType == "decimal" {
+			precision = fieldSize
+		}
+		var enumValues []string
+		if fieldType == "enum" || fieldType == "set" {
+			enumValues = parseMySQLEnumValues(row["Type"])
+		}
 
-		fieldType := submatches[1]
-		fieldSize := 0
-		if submatches[3] != "" {
-			fieldSize, err = strconv.Atoi(submatches[3])
-			if err != nil {
-				return nil, err
-			}
+		var defaultValue *string
+		if value, ok := row["Default"]; ok {
+			value = quoteMySQLDefault(fieldType, value, row["Extra"])
+			defaultValue = &value
 		}
-		unsigned := submatches[5] == "unsigned"
-
-		result = append(result, fieldDescriptor{
-			Name:      row["Field"],
-			Type:      fieldType,
-			Size:      fieldSize,
-			Unsigned:  unsigned,
-			AllowNull: row["Null"] == "YES",
-			Comment:   row["Comment"],
+
+		// DEFAULT_GENERATED is an expression default, not a generated column
+		isGenerated := strings.Contains(row["Extra"], "VIRTUAL GENERATED") || strings.Contains(row["Extra"], "STORED GENERATED")
+
+		result = append(result, FieldDescriptor{
+			Name:              row["Field"],
+			Type:              fieldType,
+			Size:              fieldSize,
+			Unsigned:          unsigned,
+			AllowNull:         row["Null"] == "YES",
+			Comment:           row["Comment"],
+			EnumValues:        enumValues,
+			Precision:         precision,
+			Scale:             scale,
+			PrimaryKeyOrdinal: primaryKeyOrdinals[row["Field"]],
+			DefaultValue:      defaultValue,
+			IsAutoIncrement:   strings.Contains(row["Extra"], "auto_increment"),
+			IsGenerated:       isGenerated,
 		})
 	}
 	return result, nil
 }
 
+var mysqlTypeRegexp = regexp.MustCompile(`^([a-z]+)(\(([0-9]+)(,([0-9]+))?\))?`)
+
+// parseMySQLType splits a column type such as int(10) unsigned zerofill or decimal(12,2) into the
+// type without display width nor attributes, its size, its scale and whether it is unsigned.
+// Zerofill columns are unsigned too.
+func parseMySQLType(columnType string) (fieldType string, size, scale int, unsigned bool) {
+	columnType = strings.ToLower(strings.TrimSpace(columnType))
+	submatches := mysqlTypeRegexp.FindStringSubmatch(columnType)
+	if submatches == nil {
+		return columnType, 0, 0, false
+	}
+	fieldType = submatches[1]
+	size, _ = strconv.Atoi(submatches[3])
+	scale, _ = strconv.Atoi(submatches[5])
+	for _, attribute := range strings.Fields(columnType[len(submatches[0]):]) {
+		if attribute == "unsigned" || attribute == "zerofill" {
+			unsigned = true
+		}
+	}
+	return
+}
+
+// quoteMySQLDefault quotes a default as MySQL reports literals unquoted. Expression defaults such
+// as CURRENT_TIMESTAMP and numbers are kept as they are.
+func quoteMySQLDefault(fieldType, defaultValue, extra string) string {
+	if strings.Contains(extra, "DEFAULT_GENERATED") {
+		// expression default since MySQL 8.0.13
+		return defaultValue
+	}
+	switch fieldType {
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set", "binary", "varbinary":
+	default:
+		if _, err := strconv.ParseFloat(defaultValue, 64); err == nil || strings.HasPrefix(strings.ToUpper(defaultValue), "CURRENT_TIMESTAMP") {
+			return defaultValue
+		}
+	}
+	return "'" + strings.ReplaceAll(defaultValue, "'", "''") + "'"
+}
+
+// parseMySQLEnumValues returns the values of a column type such as enum('a','b') or set('a','b').
+func parseMySQLEnumValues(columnType string) (values []string) {
+	start := strings.Index(columnType, "(")
+	end := strings.LastIndex(columnType, ")")
+	if start < 0 || end < start {
+		return
+	}
+	var value strings.Builder
+	inQuote := false
+	list := columnType[start+1 : end]
+	for i := 0; i < len(list); i++ {
+		c := list[i]
+		switch {
+		case c == '\'' && inQuote && i+1 < len(list) && list[i+1] == '\'':
+			value.WriteByte(c)
+			i++
+		case c == '\'':
+			if inQuote {
+				values = append(values, value.String())
+				value.Reset()
+			}
+			inQuote = !inQuote
+		case inQuote:
+			value.WriteByte(c)
+		}
+	}
+	return
+}
+
 func (m mysqlSchemaFetcher) QuoteIdentifier(identifier string) string {
-	return "`" + identifier + "`"
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+func newMySQLSchemaFetcher(db *sql.DB, options options) SchemaFetcher {
+	return mysqlSchemaFetcher{db: newQueryer(db, options)}
 }
 
-func newMySQLSchemaFetcher(db *sql.DB) schemaFetcher {
-	return mysqlSchemaFetcher{db: db}
+func (m mysqlSchemaFetcher) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	return queryForeignKeys(m.db, "SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, k.REFERENCED_TABLE_NAME, k.REFERENCED_COLUMN_NAME FROM information_schema.KEY_COLUMN_USAGE k JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND r.CONSTRAINT_NAME = k.CONSTRAINT_NAME AND r.TABLE_NAME = k.TABLE_NAME WHERE k.TABLE_SCHEMA = DATABASE() AND k.TABLE_NAME = ? AND k.REFERENCED_TABLE_SCHEMA = DATABASE() ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION", tableName)
 }
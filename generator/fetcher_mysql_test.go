@@ -0,0 +1,135 @@
+package generator
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParseMySQLEnumValues(t *testing.T) {
+	tests := []struct {
+		columnType string
+		values     []string
+	}{
+		{"set('read','write')", []string{"read", "write"}},
+		{"enum('draft','published')", []string{"draft", "published"}},
+		{"enum('it''s','a,b','(c)')", []string{"it's", "a,b", "(c)"}},
+		{"set('')", []string{""}},
+		{"varchar(20)", nil},
+		{"text", nil},
+	}
+	for _, test := range tests {
+		if values := parseMySQLEnumValues(test.columnType); !reflect.DeepEqual(values, test.values) {
+			t.Errorf("parseMySQLEnumValues(%s) = %q, want %q", test.columnType, values, test.values)
+		}
+	}
+
+	fieldDescriptor := FieldDescriptor{Name: "permissions", Type: "set", EnumValues: []string{"read", "write"}}
+	if goType := testGoType(t, "mysql", Config{}, fieldDescriptor); goType != "string" {
+		t.Errorf("getType(set) = %s, want string", goType)
+	}
+}
+
+func TestGetTypeBit(t *testing.T) {
+	tests := []struct {
+		driverName string
+		fieldType  string
+		size       int
+		goType     string
+	}{
+		{"mysql", "bit", 1, "bool"},
+		{"mysql", "bit", 8, "[]byte"},
+		{"mysql", "bit", 64, "[]byte"},
+		{"postgres", "bit", 1, "bool"},
+		{"postgres", "bit", 8, "string"},
+		{"postgres", "bit", 64, "string"},
+		{"postgres", "bit varying", 8, "string"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "flags", Type: test.fieldType, Size: test.size}
+		if goType := testGoType(t, test.driverName, Config{}, fieldDescriptor); goType != test.goType {
+			t.Errorf("%s: getType(%s(%d)) = %s, want %s", test.driverName, test.fieldType, test.size, goType, test.goType)
+		}
+	}
+}
+
+func TestScanBit(t *testing.T) {
+	// the MySQL driver returns bit fields as big-endian bytes, Postgres as bit strings
+	tests := []struct {
+		driverName string
+		size       int
+		value      driver.Value
+	}{
+		{"mysql", 8, []byte{0x05}},
+		{"mysql", 64, []byte{0x80, 0, 0, 0, 0, 0, 0, 0x01}},
+		{"postgres", 8, "00000101"},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s bit(%d)", test.driverName, test.size), func(t *testing.T) {
+			db, _ := newFakeDB(t, fakeResult{contains: "SELECT", columns: []string{"flags"}, rows: [][]driver.Value{{test.value}}})
+			goType := testGoType(t, test.driverName, Config{}, FieldDescriptor{Name: "flags", Type: "bit", Size: test.size})
+			var dest interface{}
+			switch goType {
+			case "[]byte":
+				dest = new([]byte)
+			case "string":
+				dest = new(string)
+			default:
+				t.Fatalf("%s: unexpected Go type %s of bit(%d)", test.driverName, goType, test.size)
+			}
+			if err := db.QueryRow("SELECT flags FROM settings").Scan(dest); err != nil {
+				t.Errorf("%s: scanning bit(%d) into %s: %s", test.driverName, test.size, goType, err)
+				return
+			}
+			got := reflect.ValueOf(dest).Elem().Interface()
+			if want := reflect.ValueOf(test.value).Convert(reflect.TypeOf(got)).Interface(); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: scanned bit(%d) into %v, want %v", test.driverName, test.size, got, want)
+			}
+		})
+	}
+}
+
+func TestParseMySQLType(t *testing.T) {
+	tests := []struct {
+		columnType string
+		fieldType  string
+		size       int
+		scale      int
+		unsigned   bool
+	}{
+		{"int(10) unsigned zerofill", "int", 10, 0, true},
+		{"tinyint(3) unsigned", "tinyint", 3, 0, true},
+		{"int(11)", "int", 11, 0, false},
+		{"bigint", "bigint", 0, 0, false},
+		{"smallint(5) zerofill", "smallint", 5, 0, true},
+		{"decimal(12,2) unsigned", "decimal", 12, 2, true},
+		{"VARCHAR(255)", "varchar", 255, 0, false},
+	}
+	for _, test := range tests {
+		fieldType, size, scale, unsigned := parseMySQLType(test.columnType)
+		if fieldType != test.fieldType || size != test.size || scale != test.scale || unsigned != test.unsigned {
+			t.Errorf("parseMySQLType(%s) = %s, %d, %d, %t, want %s, %d, %d, %t", test.columnType, fieldType, size, scale, unsigned, test.fieldType, test.size, test.scale, test.unsigned)
+		}
+	}
+}
+
+func TestGetTypeMySQLUnsignedDecimal(t *testing.T) {
+	fieldType, size, scale, unsigned := parseMySQLType("decimal(10,2) unsigned")
+	tests := []struct {
+		config    Config
+		allowNull bool
+		goType    string
+	}{
+		{Config{}, false, "float64"},
+		{Config{}, true, "*float64"},
+		{Config{Decimal: true}, false, "decimal.Decimal"},
+		{Config{Decimal: true}, true, "*decimal.Decimal"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "price", Type: fieldType, Size: size, Precision: size, Scale: scale, Unsigned: unsigned, AllowNull: test.allowNull}
+		if goType := testGoType(t, "mysql", test.config, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(decimal(10,2) unsigned, -decimal=%t, null %t) = %s, want %s", test.config.Decimal, test.allowNull, goType, test.goType)
+		}
+	}
+}
@@ -1,9 +1,17 @@
 package generator
 
-import "database/sql"
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+const postgresDefaultSchema = "public"
 
 type postgresSchemaFetcher struct {
-	db *sql.DB
+	db      queryer
+	schemas []string
 }
 
 func (p postgresSchemaFetcher) GetDatabaseName() (dbName string, err error) {
@@ -13,43 +21,161 @@ func (p postgresSchemaFetcher) GetDatabaseName() (dbName string, err error) {
 }
 
 func (p postgresSchemaFetcher) GetTableNames() (tableNames []string, err error) {
-	rows, err := p.db.Query("SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'")
+	return p.queryNames("SELECT table_schema, table_name FROM information_schema.tables WHERE table_schema = ANY($1) AND table_type = 'BASE TABLE'")
+}
+
+func (p postgresSchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	return p.queryNames("SELECT table_schema, table_name FROM information_schema.views WHERE table_schema = ANY($1)")
+}
+
+// queryNames returns the names of the tables in the schemas, qualified by their schema unless in public.
+func (p postgresSchemaFetcher) queryNames(query string) (names []string, err error) {
+	rows, err := p.db.Query(query, pq.Array(p.schemas))
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var name string
-		if err = rows.Scan(&name); err != nil {
+		var schema, name string
+		if err = rows.Scan(&schema, &name); err != nil {
 			return
 		}
-		tableNames = append(tableNames, name)
+		if schema != postgresDefaultSchema {
+			name = schema + "." + name
+		}
+		names = append(names, name)
 	}
 	return
 }
 
-func (p postgresSchemaFetcher) GetFieldDescriptors(tableName string) (result []fieldDescriptor, err error) {
-	rows, err := p.db.Query("SELECT column_name, is_nullable, data_type FROM information_schema.columns WHERE table_schema = 'public' AND table_name = $1", tableName)
+func (p postgresSchemaFetcher) GetFieldDescriptors(tableName string) (result []FieldDescriptor, err error) {
+	schema, name := splitPostgresTableName(tableName)
+	primaryKeyOrdinals, err := queryPrimaryKeyOrdinals(p.db, "SELECT k.column_name, k.ordinal_position FROM information_schema.table_constraints c JOIN information_schema.key_column_usage k ON k.constraint_schema = c.constraint_schema AND k.constraint_name = c.constraint_name WHERE c.constraint_type = 'PRIMARY KEY' AND c.table_schema = $1 AND c.table_name = $2", schema, name)
+	if err != nil {
+		return
+	}
+	domainTypes, err := p.queryDomainTypes(schema)
+	if err != nil {
+		return
+	}
+	rows, err := p.db.Query("SELECT column_name, is_nullable, data_type, udt_name, column_default, COALESCE(character_maximum_length, numeric_precision, 0), is_identity, is_generated, numeric_precision, numeric_scale, (SELECT attndims FROM pg_attribute WHERE attrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass AND attname = column_name) FROM information_schema.columns WHERE table_schema = $1 AND table_name = $2 ORDER BY ordinal_position", schema, name)
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var fieldDescriptor fieldDescriptor
-		var isNullable string
-		if err = rows.Scan(&fieldDescriptor.Name, &isNullable, &fieldDescriptor.Type); err != nil {
+		var fieldDescriptor FieldDescriptor
+		var isNullable, udtName, isIdentity, isGenerated string
+		var precision, scale, dimensions sql.NullInt64
+		if err = rows.Scan(&fieldDescriptor.Name, &isNullable, &fieldDescriptor.Type, &udtName, &fieldDescriptor.DefaultValue, &fieldDescriptor.Size, &isIdentity, &isGenerated, &precision, &scale, &dimensions); err != nil {
 			return
 		}
+		if fieldDescriptor.Type == "numeric" && precision.Valid {
+			// integer types have a precision too, only numeric ones keep a scale
+			fieldDescriptor.Precision, fieldDescriptor.Scale = int(precision.Int64), int(scale.Int64)
+		}
+		fieldDescriptor.IsGenerated = isGenerated == "ALWAYS"
+		// identity columns, or serial ones defaulting to the next value of their sequence
+		fieldDescriptor.IsAutoIncrement = isIdentity == "YES" || (fieldDescriptor.DefaultValue != nil && strings.HasPrefix(*fieldDescriptor.DefaultValue, "nextval("))
+		if fieldDescriptor.Type == "USER-DEFINED" {
+			// types of extensions such as citext
+			fieldDescriptor.Type = udtName
+		}
+		if fieldDescriptor.Type == "ARRAY" {
+			// the element type, such as character varying[] for _varchar
+			elementType := strings.TrimPrefix(udtName, "_")
+			if name, ok := postgresTypeNames[elementType]; ok {
+				elementType = name
+			}
+			if dimensions.Int64 < 1 {
+				dimensions.Int64 = 1
+			}
+			fieldDescriptor.Type = elementType + strings.Repeat("[]", int(dimensions.Int64))
+		}
+		// columns of a domain report the type under it, which is another domain for nested domains
+		for i := 0; i < len(domainTypes) && domainTypes[fieldDescriptor.Type] != ""; i++ {
+			fieldDescriptor.Type = domainTypes[fieldDescriptor.Type]
+		}
+		if fieldDescriptor.DefaultValue != nil {
+			*fieldDescriptor.DefaultValue = trimPostgresCast(*fieldDescriptor.DefaultValue)
+		}
 		fieldDescriptor.AllowNull = isNullable == "YES"
+		fieldDescriptor.PrimaryKeyOrdinal = primaryKeyOrdinals[fieldDescriptor.Name]
 		result = append(result, fieldDescriptor)
 	}
 	return
 }
 
+// postgresTypeNames are the names of the information schema for the internal names of types in
+// udt_name, such as integer for int4.
+var postgresTypeNames = map[string]string{
+	"int2":        "smallint",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"float4":      "real",
+	"float8":      "double precision",
+	"varchar":     "character varying",
+	"bpchar":      "character",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+}
+
+// queryDomainTypes returns the types of the domains of the schema by their names, such as text for
+// CREATE DOMAIN email AS text, so that domains of the same name in other schemas are left out.
+func (p postgresSchemaFetcher) queryDomainTypes(schema string) (domainTypes map[string]string, err error) {
+	rows, err := p.db.Query("SELECT domain_name, CASE WHEN data_type = 'USER-DEFINED' THEN udt_name ELSE data_type END FROM information_schema.domains WHERE domain_schema = $1", schema)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	domainTypes = make(map[string]string)
+	for rows.Next() {
+		var name, dataType string
+		if err = rows.Scan(&name, &dataType); err != nil {
+			return
+		}
+		domainTypes[name] = dataType
+	}
+	err = rows.Err()
+	return
+}
+
+// GetForeignKeys returns the foreign keys of the table, referenced tables outside public are
+// qualified by their schema as in GetTableNames.
+func (p postgresSchemaFetcher) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	schema, name := splitPostgresTableName(tableName)
+	return queryForeignKeys(p.db, "SELECT c.conname, a.attname, CASE WHEN rn.nspname = 'public' THEN r.relname ELSE rn.nspname || '.' || r.relname END, ra.attname FROM pg_constraint c JOIN pg_class t ON t.oid = c.conrelid JOIN pg_namespace n ON n.oid = t.relnamespace JOIN pg_class r ON r.oid = c.confrelid JOIN pg_namespace rn ON rn.oid = r.relnamespace CROSS JOIN LATERAL unnest(c.conkey, c.confkey) WITH ORDINALITY AS k(attnum, refattnum, ordinal) JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum JOIN pg_attribute ra ON ra.attrelid = c.confrelid AND ra.attnum = k.refattnum WHERE c.contype = 'f' AND n.nspname = $1 AND t.relname = $2 ORDER BY c.conname, k.ordinal", schema, name)
+}
+
 func (p postgresSchemaFetcher) QuoteIdentifier(identifier string) string {
-	return "\"" + identifier + "\""
+	return "\"" + strings.ReplaceAll(identifier, "\"", "\"\"") + "\""
+}
+
+// trimPostgresCast trims the cast Postgres adds to literal defaults, such as 'text'::character varying.
+func trimPostgresCast(defaultValue string) string {
+	if strings.HasPrefix(defaultValue, "'") {
+		if end := strings.LastIndex(defaultValue, "'::"); end > 0 {
+			return defaultValue[:end+1]
+		}
+	}
+	return defaultValue
 }
 
-func newPostgresSchemaFetcher(db *sql.DB) schemaFetcher {
-	return postgresSchemaFetcher{db: db}
+// splitPostgresTableName splits a table name qualified as schema.table, table names without schema
+// are in public.
+func splitPostgresTableName(tableName string) (schema, name string) {
+	if schema, name, ok := strings.Cut(tableName, "."); ok {
+		return schema, name
+	}
+	return postgresDefaultSchema, tableName
+}
+
+func newPostgresSchemaFetcher(db *sql.DB, options options) SchemaFetcher {
+	schemas := options.schemas
+	if len(schemas) == 0 {
+		schemas = []string{postgresDefaultSchema}
+	}
+	return postgresSchemaFetcher{db: newQueryer(db, options), schemas: schemas}
 }
@@ -0,0 +1,125 @@
+package generator
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+// postgresColumnsResult answers the column query of the Postgres fetcher with rows of column_name,
+// is_nullable, data_type, udt_name, column_default, the size, is_identity, is_generated,
+// numeric_precision, numeric_scale and attndims.
+func postgresColumnsResult(rows ...[]driver.Value) fakeResult {
+	return fakeResult{
+		contains: "FROM information_schema.columns",
+		columns:  []string{"column_name", "is_nullable", "data_type", "udt_name", "column_default", "size", "is_identity", "is_generated", "numeric_precision", "numeric_scale", "attndims"},
+		rows:     rows,
+	}
+}
+
+// newTestPostgresSchemaFetcher returns the Postgres schema fetcher of a fake database answering with results.
+func newTestPostgresSchemaFetcher(t *testing.T, config Config, results ...fakeResult) (SchemaFetcher, *fakeDatabase) {
+	t.Helper()
+	options, err := newOptions("postgres", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, database := newFakeDB(t, results...)
+	return newPostgresSchemaFetcher(db, options), database
+}
+
+func TestPostgresSize(t *testing.T) {
+	schemaFetcher, _ := newTestPostgresSchemaFetcher(t, Config{}, postgresColumnsResult(
+		[]driver.Value{"name", "NO", "character varying", "varchar", nil, int64(64), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"code", "YES", "character", "bpchar", nil, int64(3), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"price", "NO", "numeric", "numeric", nil, int64(12), "NO", "NEVER", int64(12), int64(2), int64(0)},
+		[]driver.Value{"bio", "YES", "text", "text", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+	))
+	fieldDescriptors, err := schemaFetcher.GetFieldDescriptors("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		size      int
+		precision int
+		scale     int
+	}{
+		{"name", 64, 0, 0},
+		{"code", 3, 0, 0},
+		{"price", 12, 12, 2},
+		{"bio", 0, 0, 0},
+	}
+	if len(fieldDescriptors) != len(tests) {
+		t.Fatalf("GetFieldDescriptors() = %+v, want %d columns", fieldDescriptors, len(tests))
+	}
+	for i, test := range tests {
+		fieldDescriptor := fieldDescriptors[i]
+		if fieldDescriptor.Name != test.name || fieldDescriptor.Size != test.size || fieldDescriptor.Precision != test.precision || fieldDescriptor.Scale != test.scale {
+			t.Errorf("column %d = %+v, want %s of size %d and precision (%d,%d)", i, fieldDescriptor, test.name, test.size, test.precision, test.scale)
+		}
+	}
+}
+
+func TestPostgresDomains(t *testing.T) {
+	schemaFetcher, database := newTestPostgresSchemaFetcher(t, Config{Schemas: []string{"app", "billing"}},
+		fakeResult{
+			contains: "FROM information_schema.domains",
+			columns:  []string{"domain_name", "data_type"},
+			rows:     [][]driver.Value{{"email", "text"}, {"work_email", "email"}},
+		},
+		postgresColumnsResult(
+			[]driver.Value{"address", "NO", "email", "email", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+			[]driver.Value{"work_address", "YES", "work_email", "work_email", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+		),
+	)
+	fieldDescriptors, err := schemaFetcher.GetFieldDescriptors("billing.users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fieldDescriptors) != 2 || fieldDescriptors[0].Type != "text" || fieldDescriptors[1].Type != "text" {
+		t.Errorf("GetFieldDescriptors() = %+v, want the text type under the domains", fieldDescriptors)
+	}
+	query, ok := database.query("FROM information_schema.domains")
+	if !ok || len(query.args) != 1 || query.args[0] != "billing" {
+		t.Errorf("the domains were queried with %v, want the schema billing", query.args)
+	}
+}
+
+func TestPostgresTemporalTypes(t *testing.T) {
+	schemaFetcher, _ := newTestPostgresSchemaFetcher(t, Config{}, postgresColumnsResult(
+		[]driver.Value{"created_at", "NO", "timestamp with time zone", "timestamptz", "now()", int64(0), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"starts_at", "NO", "time without time zone", "time", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"history", "YES", "ARRAY", "_timestamptz", nil, int64(0), "NO", "NEVER", nil, nil, int64(1)},
+	))
+	fieldDescriptors, err := schemaFetcher.GetFieldDescriptors("events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fieldTypes []string
+	for _, fieldDescriptor := range fieldDescriptors {
+		fieldTypes = append(fieldTypes, fieldDescriptor.Type)
+	}
+	if want := []string{"timestamp with time zone", "time without time zone", "timestamp with time zone[]"}; !reflect.DeepEqual(fieldTypes, want) {
+		t.Errorf("GetFieldDescriptors() gave the types %q, want %q", fieldTypes, want)
+	}
+}
+
+func TestPostgresSystemTypes(t *testing.T) {
+	schemaFetcher, _ := newTestPostgresSchemaFetcher(t, Config{}, postgresColumnsResult(
+		[]driver.Value{"image", "NO", "oid", "oid", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"transaction", "YES", "xid", "xid", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+		[]driver.Value{"location", "NO", "tid", "tid", nil, int64(0), "NO", "NEVER", nil, nil, int64(0)},
+	))
+	fieldDescriptors, err := schemaFetcher.GetFieldDescriptors("files")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var goTypes []string
+	for _, fieldDescriptor := range fieldDescriptors {
+		goTypes = append(goTypes, testGoType(t, "postgres", Config{}, fieldDescriptor))
+	}
+	if want := []string{"uint32", "*uint32", "string"}; !reflect.DeepEqual(goTypes, want) {
+		t.Errorf("the system columns have the Go types %q, want %q", goTypes, want)
+	}
+}
@@ -1,18 +1,40 @@
 package generator
 
-import "database/sql"
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+)
 
 type sqlite3SchemaFetcher struct {
-	db *sql.DB
+	db queryer
 }
 
+// GetDatabaseName returns the base name of the database file, such as app for ./app.db, or main for
+// an in-memory database.
 func (s sqlite3SchemaFetcher) GetDatabaseName() (dbName string, err error) {
-	dbName = "main"
+	var seq int
+	var name, file string
+	if err = s.db.QueryRow("SELECT `seq`, `name`, `file` FROM pragma_database_list WHERE `name` = 'main'").Scan(&seq, &name, &file); err != nil {
+		return
+	}
+	dbName = strings.ToLower(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)))
+	if file == "" || dbName == "" {
+		dbName = name
+	}
 	return
 }
 
 func (s sqlite3SchemaFetcher) GetTableNames() (tableNames []string, err error) {
-	rows, err := s.db.Query("SELECT `name` FROM `sqlite_master` WHERE `type` ='table' AND `name` NOT LIKE 'sqlite_%'")
+	return s.getNames("table")
+}
+
+func (s sqlite3SchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	return s.getNames("view")
+}
+
+func (s sqlite3SchemaFetcher) getNames(objectType string) (names []string, err error) {
+	rows, err := s.db.Query("SELECT `name` FROM `sqlite_master` WHERE `type` = ? AND `name` NOT LIKE 'sqlite_%'", objectType)
 	if err != nil {
 		return
 	}
@@ -22,33 +44,70 @@ func (s sqlite3SchemaFetcher) GetTableNames() (tableNames []string, err error) {
 		if err = rows.Scan(&name); err != nil {
 			return
 		}
-		tableNames = append(tableNames, name)
+		names = append(names, name)
 	}
 	return
 }
 
-func (s sqlite3SchemaFetcher) GetFieldDescriptors(tableName string) (result []fieldDescriptor, err error) {
-	rows, err := s.db.Query("SELECT `name`, `type`, `notnull` FROM pragma_table_info('" + tableName + "')")
+func (s sqlite3SchemaFetcher) GetFieldDescriptors(tableName string) (result []FieldDescriptor, err error) {
+	// table_xinfo also lists generated columns, hidden is 2 or 3 for them
+	rows, err := s.db.Query("SELECT `name`, `type`, `notnull`, `pk`, `dflt_value`, `hidden` FROM pragma_table_xinfo(?) WHERE `hidden` != 1 ORDER BY `cid`", tableName)
 	if err != nil {
 		return
 	}
 	defer rows.Close()
 	for rows.Next() {
-		var fieldDescriptor fieldDescriptor
-		var notNull int
-		if err = rows.Scan(&fieldDescriptor.Name, &fieldDescriptor.Type, &notNull); err != nil {
+		var fieldDescriptor FieldDescriptor
+		var notNull, hidden int
+		if err = rows.Scan(&fieldDescriptor.Name, &fieldDescriptor.Type, &notNull, &fieldDescriptor.PrimaryKeyOrdinal, &fieldDescriptor.DefaultValue, &hidden); err != nil {
 			return
 		}
+		fieldDescriptor.IsGenerated = hidden != 0
 		fieldDescriptor.AllowNull = notNull == 0
 		result = append(result, fieldDescriptor)
 	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	// a single column INTEGER PRIMARY KEY is an alias of the rowid, which is never null and assigned
+	// on insert, unless the table is WITHOUT ROWID or the key is otherwise backed by an index of its
+	// own, such as a PRIMARY KEY DESC. AUTOINCREMENT is only allowed on an alias of the rowid.
+	var primaryKeys []int
+	for i, fieldDescriptor := range result {
+		if fieldDescriptor.PrimaryKeyOrdinal != 0 {
+			primaryKeys = append(primaryKeys, i)
+		}
+	}
+	if len(primaryKeys) != 1 || !strings.EqualFold(result[primaryKeys[0]].Type, "integer") {
+		return
+	}
+	isRowID, err := s.isRowIDAlias(tableName)
+	if err != nil || !isRowID {
+		return
+	}
+	result[primaryKeys[0]].AllowNull = false
+	result[primaryKeys[0]].IsAutoIncrement = true
 	return
 }
 
+// isRowIDAlias reports whether the INTEGER PRIMARY KEY of the table is an alias of the rowid, that is
+// whether the key has no index of its own, which the key of a WITHOUT ROWID table always has.
+func (s sqlite3SchemaFetcher) isRowIDAlias(tableName string) (bool, error) {
+	var count int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM pragma_index_list(?) WHERE `origin` = 'pk'", tableName).Scan(&count); err != nil {
+		return false, err
+	}
+	return count == 0, nil
+}
+
 func (s sqlite3SchemaFetcher) QuoteIdentifier(identifier string) string {
-	return "\"" + identifier + "\""
+	return "\"" + strings.ReplaceAll(identifier, "\"", "\"\"") + "\""
+}
+
+func newSQLite3SchemaFetcher(db *sql.DB, options options) SchemaFetcher {
+	return sqlite3SchemaFetcher{db: newQueryer(db, options)}
 }
 
-func newSQLite3SchemaFetcher(db *sql.DB) schemaFetcher {
-	return sqlite3SchemaFetcher{db: db}
+func (s sqlite3SchemaFetcher) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	return queryForeignKeys(s.db, "SELECT `id`, `from`, `table`, `to` FROM pragma_foreign_key_list(?) ORDER BY `id`, `seq`", tableName)
 }
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSQLite3AutoIncrement(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY AUTOINCREMENT, title TEXT)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, name TEXT) WITHOUT ROWID",
+		"CREATE TABLE events (id INTEGER PRIMARY KEY DESC, name TEXT)",
+		"CREATE TABLE codes (id INT PRIMARY KEY, name TEXT)",
+		"CREATE TABLE pairs (a INTEGER, b INTEGER, PRIMARY KEY (a, b))",
+	)
+	options, err := newOptions("sqlite3", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaFetcher := newTestSchemaFetcher(t, dataSourceName, options)
+	tests := []struct {
+		tableName       string
+		isAutoIncrement bool
+	}{
+		{"users", true},
+		{"posts", true},
+		{"tags", false},
+		{"events", false},
+		{"codes", false},
+		{"pairs", false},
+	}
+	for _, test := range tests {
+		fieldDescriptors, err := schemaFetcher.GetFieldDescriptors(test.tableName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fieldDescriptors[0].IsAutoIncrement; got != test.isAutoIncrement {
+			t.Errorf("%s: IsAutoIncrement = %v, want %v", test.tableName, got, test.isAutoIncrement)
+		}
+		if test.isAutoIncrement && fieldDescriptors[0].AllowNull {
+			t.Errorf("%s: AllowNull = true for an alias of the rowid", test.tableName)
+		}
+	}
+}
+
+func TestSQLite3CompositePrimaryKey(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE user_roles (role_id INTEGER NOT NULL, user_id INTEGER NOT NULL, PRIMARY KEY (user_id, role_id))")
+	options, err := newOptions("sqlite3", Config{Tags: []string{"gorm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fieldDescriptors, err := newTestSchemaFetcher(t, dataSourceName, options).GetFieldDescriptors("user_roles")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name              string
+		primaryKeyOrdinal int
+		tag               string
+	}{
+		{"role_id", 2, "`gorm:\"column:role_id;primaryKey\"`"},
+		{"user_id", 1, "`gorm:\"column:user_id;primaryKey\"`"},
+	}
+	for i, test := range tests {
+		fieldDescriptor := fieldDescriptors[i]
+		if fieldDescriptor.Name != test.name || fieldDescriptor.PrimaryKeyOrdinal != test.primaryKeyOrdinal || fieldDescriptor.IsAutoIncrement {
+			t.Errorf("column %d = %+v, want %s at ordinal %d of the key", i, fieldDescriptor, test.name, test.primaryKeyOrdinal)
+		}
+		if tag := getTag(fieldDescriptor, "int64", options); tag != test.tag {
+			t.Errorf("getTag(%s) = %s, want %s", test.name, tag, test.tag)
+		}
+	}
+}
+
+func TestSQLite3IntegerPrimaryKeyIsNotNull(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE t (id INTEGER PRIMARY KEY, name TEXT)", "CREATE TABLE codes (code TEXT PRIMARY KEY)")
+	files := generateTestFiles(t, dataSourceName, Config{})
+	tests := []struct {
+		fileName string
+		field    string
+	}{
+		{"t.go", "\tId int64 ``\n"},
+		{"t.go", "\tName *string ``\n"},
+		// other primary keys may be null in SQLite
+		{"codes.go", "\tCode *string ``\n"},
+	}
+	for _, test := range tests {
+		if !strings.Contains(files[test.fileName], test.field) {
+			t.Errorf("%s = %q, want %q", test.fileName, files[test.fileName], test.field)
+		}
+	}
+}
+
+func TestSQLite3DatabaseName(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name        string
+		config      Config
+		packageName string
+	}{
+		{"Shop", Config{}, "shop"},
+		{"my-shop", Config{}, "my_shop"},
+		{"shop", Config{Package: "models"}, "models"},
+	}
+	for _, test := range tests {
+		dataSourceName := newNamedTestDatabase(t, dir, test.name, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+		file := generateTestFiles(t, dataSourceName, test.config)["users.go"]
+		if !strings.Contains(file, "\npackage "+test.packageName+" \n") {
+			t.Errorf("%s.db: users.go = %q, want the package %s", test.name, file, test.packageName)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"database/sql"
+	"strings"
+)
+
+type sqlServerSchemaFetcher struct {
+	db queryer
+}
+
+func (s sqlServerSchemaFetcher) GetDatabaseName() (dbName string, err error) {
+	row := s.db.QueryRow("SELECT DB_NAME()")
+	err = row.Scan(&dbName)
+	return
+}
+
+func (s sqlServerSchemaFetcher) GetTableNames() (tableNames []string, err error) {
+	return s.getTableNames("BASE TABLE")
+}
+
+func (s sqlServerSchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	return s.getTableNames("VIEW")
+}
+
+func (s sqlServerSchemaFetcher) getTableNames(tableType string) (tableNames []string, err error) {
+	rows, err := s.db.Query("SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA = SCHEMA_NAME() AND TABLE_TYPE = @p1", tableType)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err = rows.Scan(&name); err != nil {
+			return
+		}
+		tableNames = append(tableNames, name)
+	}
+	return
+}
+
+func (s sqlServerSchemaFetcher) GetFieldDescriptors(tableName string) (result []FieldDescriptor, err error) {
+	primaryKeyOrdinals, err := queryPrimaryKeyOrdinals(s.db, "SELECT k.COLUMN_NAME, k.ORDINAL_POSITION FROM INFORMATION_SCHEMA.TABLE_CONSTRAINTS c JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k ON k.CONSTRAINT_SCHEMA = c.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = c.CONSTRAINT_NAME WHERE c.CONSTRAINT_TYPE = 'PRIMARY KEY' AND c.TABLE_SCHEMA = SCHEMA_NAME() AND c.TABLE_NAME = @p1", tableName)
+	if err != nil {
+		return
+	}
+	rows, err := s.db.Query("SELECT COLUMN_NAME, DATA_TYPE, CHARACTER_MAXIMUM_LENGTH, IS_NULLABLE, COLUMN_DEFAULT, COLUMNPROPERTY(OBJECT_ID(QUOTENAME(TABLE_SCHEMA) + '.' + QUOTENAME(TABLE_NAME)), COLUMN_NAME, 'IsIdentity'), NUMERIC_PRECISION, NUMERIC_SCALE FROM INFORMATION_SCHEMA.COLUMNS WHERE TABLE_SCHEMA = SCHEMA_NAME() AND TABLE_NAME = @p1 ORDER BY ORDINAL_POSITION", tableName)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var fieldDescriptor FieldDescriptor
+		var maxLength sql.NullInt64
+		var isNullable string
+		var isIdentity, precision, scale sql.NullInt64
+		if err = rows.Scan(&fieldDescriptor.Name, &fieldDescriptor.Type, &maxLength, &isNullable, &fieldDescriptor.DefaultValue, &isIdentity, &precision, &scale); err != nil {
+			return
+		}
+		if fieldDescriptor.Type == "decimal" || fieldDescriptor.Type == "numeric" {
+			fieldDescriptor.Precision, fieldDescriptor.Scale = int(precision.Int64), int(scale.Int64)
+		}
+		fieldDescriptor.IsAutoIncrement = isIdentity.Int64 == 1
+		if fieldDescriptor.DefaultValue != nil {
+			*fieldDescriptor.DefaultValue = trimSQLServerParentheses(*fieldDescriptor.DefaultValue)
+		}
+		fieldDescriptor.Size = int(maxLength.Int64)
+		fieldDescriptor.AllowNull = isNullable == "YES"
+		fieldDescriptor.PrimaryKeyOrdinal = primaryKeyOrdinals[fieldDescriptor.Name]
+		switch fieldDescriptor.Type {
+		case "bit":
+			// a single bit, mapped to bool
+			fieldDescriptor.Size = 1
+		case "tinyint":
+			// tinyint ranges from 0 to 255 in SQL Server
+			fieldDescriptor.Unsigned = true
+		}
+		result = append(result, fieldDescriptor)
+	}
+	return
+}
+
+// trimSQLServerParentheses trims the parentheses SQL Server wraps defaults in, such as ((0)).
+func trimSQLServerParentheses(defaultValue string) string {
+	for len(defaultValue) >= 2 && defaultValue[0] == '(' && defaultValue[len(defaultValue)-1] == ')' {
+		depth := 0
+		for i := 0; i < len(defaultValue)-1; i++ {
+			if defaultValue[i] == '(' {
+				depth++
+			} else if defaultValue[i] == ')' {
+				depth--
+			}
+			if depth == 0 {
+				// the first parenthesis is closed before the end, as in (a)+(b)
+				return defaultValue
+			}
+		}
+		defaultValue = defaultValue[1 : len(defaultValue)-1]
+	}
+	return defaultValue
+}
+
+func (s sqlServerSchemaFetcher) QuoteIdentifier(identifier string) string {
+	return "[" + strings.ReplaceAll(identifier, "]", "]]") + "]"
+}
+
+func newSQLServerSchemaFetcher(db *sql.DB, options options) SchemaFetcher {
+	return sqlServerSchemaFetcher{db: newQueryer(db, options)}
+}
+
+func (s sqlServerSchemaFetcher) GetForeignKeys(tableName string) ([]ForeignKey, error) {
+	return queryForeignKeys(s.db, "SELECT k.CONSTRAINT_NAME, k.COLUMN_NAME, u.TABLE_NAME, u.COLUMN_NAME FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS r JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE k ON k.CONSTRAINT_SCHEMA = r.CONSTRAINT_SCHEMA AND k.CONSTRAINT_NAME = r.CONSTRAINT_NAME JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE u ON u.CONSTRAINT_SCHEMA = r.UNIQUE_CONSTRAINT_SCHEMA AND u.CONSTRAINT_NAME = r.UNIQUE_CONSTRAINT_NAME AND u.ORDINAL_POSITION = k.ORDINAL_POSITION WHERE k.TABLE_SCHEMA = SCHEMA_NAME() AND k.TABLE_NAME = @p1 ORDER BY k.CONSTRAINT_NAME, k.ORDINAL_POSITION", tableName)
+}
@@ -0,0 +1,89 @@
+package generator
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+func TestSQLServerFieldDescriptors(t *testing.T) {
+	options, err := newOptions("sqlserver", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, database := newFakeDB(t,
+		fakeResult{
+			contains: "CONSTRAINT_TYPE = 'PRIMARY KEY'",
+			columns:  []string{"COLUMN_NAME", "ORDINAL_POSITION"},
+			rows:     [][]driver.Value{{"id", int64(1)}},
+		},
+		fakeResult{
+			contains: "FROM INFORMATION_SCHEMA.COLUMNS",
+			columns:  []string{"COLUMN_NAME", "DATA_TYPE", "CHARACTER_MAXIMUM_LENGTH", "IS_NULLABLE", "COLUMN_DEFAULT", "IsIdentity", "NUMERIC_PRECISION", "NUMERIC_SCALE"},
+			rows: [][]driver.Value{
+				{"id", "int", nil, "NO", nil, int64(1), int64(10), int64(0)},
+				{"name", "nvarchar", int64(50), "YES", nil, int64(0), nil, nil},
+				{"active", "bit", nil, "NO", "((1))", int64(0), nil, nil},
+				{"level", "tinyint", nil, "NO", nil, int64(0), int64(3), int64(0)},
+				{"price", "decimal", nil, "NO", nil, int64(0), int64(10), int64(2)},
+				{"token", "uniqueidentifier", nil, "NO", "(newid())", int64(0), nil, nil},
+				{"starts_at", "datetime2", nil, "YES", nil, int64(0), nil, nil},
+				{"balance", "money", nil, "NO", nil, int64(0), int64(19), int64(4)},
+			},
+		},
+	)
+	fieldDescriptors, err := newSQLServerSchemaFetcher(db, options).GetFieldDescriptors("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		goType  string
+		comment string
+	}{
+		{"id", "int32", ""},
+		{"name", "*string", ""},
+		{"active", "bool", "default: 1"},
+		{"level", "uint8", ""},
+		{"price", "float64", "decimal(10,2)"},
+		{"token", "string", "default: newid()"},
+		{"starts_at", "*time.Time", ""},
+		{"balance", "string", ""},
+	}
+	if len(fieldDescriptors) != len(tests) {
+		t.Fatalf("GetFieldDescriptors() = %+v, want %d columns", fieldDescriptors, len(tests))
+	}
+	for i, test := range tests {
+		fieldDescriptor := fieldDescriptors[i]
+		goType := testGoType(t, "sqlserver", Config{}, fieldDescriptor)
+		comment := newFieldInfo(fieldDescriptor, "", goType, nil, options).TrailingComment
+		if fieldDescriptor.Name != test.name || goType != test.goType || comment != test.comment {
+			t.Errorf("column %d is %s %s // %s, want %s %s // %s", i, fieldDescriptor.Name, goType, comment, test.name, test.goType, test.comment)
+		}
+	}
+	if !fieldDescriptors[0].IsAutoIncrement || fieldDescriptors[0].PrimaryKeyOrdinal != 1 || fieldDescriptors[1].Size != 50 {
+		t.Errorf("GetFieldDescriptors() = %+v, want the identity primary key id and name of size 50", fieldDescriptors)
+	}
+	if query, ok := database.query("FROM INFORMATION_SCHEMA.COLUMNS"); !ok || !reflect.DeepEqual(query.args, []driver.Value{"users"}) {
+		t.Errorf("the column query got %v, want the table users", query.args)
+	}
+}
+
+func TestTrimSQLServerParentheses(t *testing.T) {
+	tests := []struct {
+		defaultValue string
+		want         string
+	}{
+		{"((0))", "0"},
+		{"('new')", "'new'"},
+		{"(getdate())", "getdate()"},
+		{"((1)+(2))", "(1)+(2)"},
+		{"(1)+(2)", "(1)+(2)"},
+		{"0", "0"},
+	}
+	for _, test := range tests {
+		if got := trimSQLServerParentheses(test.defaultValue); got != test.want {
+			t.Errorf("trimSQLServerParentheses(%s) = %s, want %s", test.defaultValue, got, test.want)
+		}
+	}
+}
@@ -6,41 +6,103 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"go/token"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 	"unicode"
+	"unicode/utf8"
 )
 
-type schemaFetcher interface {
+// SchemaFetcher reads the schema of a database. The fetchers of the built-in drivers implement it,
+// custom ones can be used by GenerateWithFetcher.
+type SchemaFetcher interface {
+	// GetDatabaseName returns the name of the database, used as package name.
 	GetDatabaseName() (dbName string, err error)
 	GetTableNames() (tableNames []string, err error)
-	GetFieldDescriptors(tableName string) ([]fieldDescriptor, error)
+	GetViewNames() (viewNames []string, err error)
+	// GetFieldDescriptors returns the columns of the table in order.
+	GetFieldDescriptors(tableName string) ([]FieldDescriptor, error)
 	QuoteIdentifier(identifier string) string
 }
 
-type fieldDescriptor struct {
-	Name      string
+// FieldDescriptor describes a column of a table.
+type FieldDescriptor struct {
+	Name string
+	// SQL type without size nor modifiers, such as varchar or int
 	Type      string
 	Size      int
 	Unsigned  bool
 	AllowNull bool
 	Comment   string
+	// 1-based position of the column in the primary key, 0 if not in it
+	PrimaryKeyOrdinal int
+	// values of enum and set columns
+	EnumValues []string
+	// precision and scale of decimal and numeric columns
+	Precision int
+	Scale     int
+	// default of the column as an SQL expression, such as 0, 'text' or CURRENT_TIMESTAMP, nil if
+	// the column has no default
+	DefaultValue *string
+	// whether values of the column are generated by the database, such as auto_increment or serial
+	IsAutoIncrement bool
+	// whether the column is computed from others, such as GENERATED ALWAYS AS columns, so it is read-only
+	IsGenerated bool
 }
 
-func getSchemaFetcherFactory(driverName string) func(db *sql.DB) schemaFetcher {
-	switch driverName {
-	case "mysql":
-		return newMySQLSchemaFetcher
-	case "sqlite3":
-		return newSQLite3SchemaFetcher
-	case "postgres":
-		return newPostgresSchemaFetcher
-	default:
-		_, _ = fmt.Fprintln(os.Stderr, "unsupported driver "+driverName)
-		os.Exit(2)
-		return nil
+// queryPrimaryKeyOrdinals runs a query selecting the columns of a primary key and their positions.
+func queryPrimaryKeyOrdinals(db queryer, query string, args ...interface{}) (ordinals map[string]int, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	ordinals = make(map[string]int)
+	for rows.Next() {
+		var column string
+		var ordinal int
+		if err = rows.Scan(&column, &ordinal); err != nil {
+			return
+		}
+		ordinals[column] = ordinal
+	}
+	err = rows.Err()
+	return
+}
+
+// schemaFetcherFactories maps the supported driver names to the factories of their SchemaFetcher.
+var schemaFetcherFactories = map[string]func(db *sql.DB, options options) SchemaFetcher{
+	"mysql":      newMySQLSchemaFetcher,
+	"sqlite3":    newSQLite3SchemaFetcher,
+	"postgres":   newPostgresSchemaFetcher,
+	"sqlserver":  newSQLServerSchemaFetcher,
+	"mssql":      newSQLServerSchemaFetcher,
+	"clickhouse": newClickHouseSchemaFetcher,
+}
+
+func getSchemaFetcherFactory(driverName string) (func(db *sql.DB, options options) SchemaFetcher, error) {
+	schemaFetcherFactory, ok := schemaFetcherFactories[driverName]
+	if !ok {
+		return nil, fmt.Errorf("%w %s, supported drivers are %s", ErrUnsupportedDriver, driverName, strings.Join(SupportedDrivers(), ", "))
 	}
+	return schemaFetcherFactory, nil
+}
+
+// SupportedDrivers returns the sorted names of the drivers with a built-in SchemaFetcher.
+func SupportedDrivers() []string {
+	driverNames := make([]string, 0, len(schemaFetcherFactories))
+	for driverName := range schemaFetcherFactories {
+		driverNames = append(driverNames, driverName)
+	}
+	sort.Strings(driverNames)
+	return driverNames
 }
 
 var nonIdentifierRegexp = regexp.MustCompile(`\W`)
@@ -53,14 +115,42 @@ func ensureIdentifier(name string) string {
 	return result
 }
 
-func newBuffWithBaseHeader(dbName string) *bytes.Buffer {
+// defaultMarker is the line marking the generated files, in the format recognized by Go tools,
+// see https://go.dev/s/generatedcode.
+const defaultMarker = "// Code generated by sqlmodel; DO NOT EDIT."
+
+func newBuffWithBaseHeader(dbName string, options options) *bytes.Buffer {
 	var buf bytes.Buffer
-	buf.WriteString("// This file is generated by sqlmodel (https://github.com/Ficoto/sqlmodel)\n")
-	buf.WriteString("// DO NOT EDIT.\n")
+	buf.WriteString(getFileHeader(options))
 	buf.WriteString(fmt.Sprintf("package %s \n\n", ensureIdentifier(dbName)))
 	return &buf
 }
 
+// getFileHeader returns the lines of the generated files before the package clause.
+func getFileHeader(options options) string {
+	var buf strings.Builder
+	if len(options.buildConstraints) != 0 {
+		for _, line := range options.buildConstraints {
+			buf.WriteString(line + "\n")
+		}
+		buf.WriteString("\n")
+	}
+	if options.header != "" {
+		buf.WriteString(options.header)
+		buf.WriteString("\n")
+	}
+	if options.header == "" || !options.headerReplace {
+		buf.WriteString(options.marker + "\n")
+	}
+	return buf.String()
+}
+
+// isInteractive reports whether stdin is a terminal, otherwise -overwrite prompt doesn't overwrite.
+func isInteractive() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
 func pathExists(path string) (bool, error) {
 	_, err := os.Stat(path)
 	if err == nil {
@@ -72,15 +162,18 @@ func pathExists(path string) (bool, error) {
 	return false, err
 }
 
-func writeToFile(buffer *bytes.Buffer, outputFile string, force bool) error {
+// writeToFile writes the file, asking whether to overwrite an existing one with -overwrite prompt.
+func writeToFile(buffer *bytes.Buffer, outputFile string, options options) error {
 	exists, _ := pathExists(outputFile)
-	if exists && !force {
-		var override string
-		fmt.Fprint(os.Stdout, fmt.Sprintf("file(%s) already exists，is overwritten(Y/N)? ", outputFile))
-		fmt.Scanln(&override)
+	if exists && options.overwrite != "always" {
+		overwrite := "N"
+		if options.overwrite == "prompt" && isInteractive() {
+			fmt.Fprint(os.Stdout, fmt.Sprintf("file(%s) already exists，is overwritten(Y/N)? ", outputFile))
+			fmt.Scanln(&overwrite)
+		}
 
-		if override != "Y" && override != "y" {
-			fmt.Fprintln(os.Stdout, "skip"+outputFile)
+		if overwrite != "Y" && overwrite != "y" {
+			options.logger.Printf("skip %s: already exists", outputFile)
 			return nil
 		}
 	}
@@ -97,21 +190,216 @@ func writeToFile(buffer *bytes.Buffer, outputFile string, force bool) error {
 	return nil
 }
 
+// writeToWriter writes the file to the writer Config.WriterFor returns for name and closes it.
+func writeToWriter(buffer *bytes.Buffer, name string, options options) error {
+	w, err := options.writerFor(name)
+	if err != nil {
+		return err
+	}
+	if _, err = buffer.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+var illegalFileNameRegexp = regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
+
+// getFileName returns the name, without extension, of the file generated for the table.
+func getFileName(tableName, className, fileCase string) string {
+	var name string
+	switch fileCase {
+	case "snake":
+		name = convertToSnakeCase(tableName)
+	case "struct":
+		name = className
+	default:
+		name = tableName
+	}
+	return illegalFileNameRegexp.ReplaceAllString(name, "_")
+}
+
+// sourceFile accumulates the sources of several files into a single one.
+type sourceFile struct {
+	imports []string
+	body    bytes.Buffer
+}
+
+func (f *sourceFile) add(imports []string, body []byte) {
+	f.imports = appendImports(f.imports, imports...)
+	f.body.Write(body)
+}
+
+func (f *sourceFile) writeTo(w io.Writer, dbName string, options options) error {
+	var buf = newBuffWithBaseHeader(dbName, options)
+	writeImports(buf, f.imports)
+	buf.Write(f.body.Bytes())
+	buf, err := postProcess("", buf, options)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// writeSource writes a file of the output path with the imports and body, or adds them to the
+// single file written to stdout with -stdout. tableName is the table the file is generated from,
+// empty for the other files.
+func writeSource(dbName, tableName, fileName string, imports []string, body []byte, options options) error {
+	if options.stdout != nil {
+		options.stdout.add(imports, body)
+		return nil
+	}
+	var buf = newBuffWithBaseHeader(dbName, options)
+	writeImports(buf, imports)
+	buf.Write(body)
+	buf, err := postProcess(tableName, buf, options)
+	if err != nil {
+		return err
+	}
+	writerName := tableName
+	if writerName == "" {
+		writerName = fileName + ".go"
+	}
+	return writeOutputFile(buf, fmt.Sprintf("%s/%s.go", options.outputPath, fileName), writerName, options)
+}
+
+// postProcess runs Config.PostProcess on the source of a file before it is written.
+func postProcess(tableName string, buffer *bytes.Buffer, options options) (*bytes.Buffer, error) {
+	if options.postProcess == nil {
+		return buffer, nil
+	}
+	src, err := options.postProcess(tableName, buffer.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("post process: %w", err)
+	}
+	return bytes.NewBuffer(src), nil
+}
+
+// writeOutputFile writes a generated file, reports it with -dryrun, or with -changed only writes
+// and reports it if it differs from the existing file. With Config.WriterFor, it is written to the
+// writer for writerName instead.
+func writeOutputFile(buffer *bytes.Buffer, outputFile, writerName string, options options) error {
+	if options.writerFor != nil {
+		return writeToWriter(buffer, writerName, options)
+	}
+	if !options.dryRun && !options.changed {
+		return writeToFile(buffer, outputFile, options)
+	}
+	status, err := getFileStatus(buffer, outputFile)
+	if err != nil || (options.changed && status == "unchanged") {
+		return err
+	}
+	if options.dryRun {
+		_, err = fmt.Fprintf(os.Stdout, "%s: %d bytes, %s\n", outputFile, buffer.Len(), status)
+		return err
+	}
+	if _, err = fmt.Fprintf(os.Stdout, "%s: %s\n", outputFile, status); err != nil {
+		return err
+	}
+	// -changed writes the files it reports, whatever -overwrite
+	options.overwrite = "always"
+	return writeToFile(buffer, outputFile, options)
+}
+
+// getFileStatus returns how the generated file compares to the existing one: new, changed or unchanged.
+func getFileStatus(buffer *bytes.Buffer, outputFile string) (string, error) {
+	existing, err := os.ReadFile(outputFile)
+	switch {
+	case err == nil && bytes.Equal(existing, buffer.Bytes()):
+		return "unchanged", nil
+	case err == nil:
+		return "changed", nil
+	case os.IsNotExist(err):
+		return "new", nil
+	}
+	return "", err
+}
+
+func convertToSnakeCase(s string) string {
+	var result []rune
+	nextCharStartsWord := false
+	var prev rune
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if len(result) != 0 && (nextCharStartsWord || (unicode.IsUpper(r) && unicode.IsLower(prev))) {
+				result = append(result, '_')
+			}
+			result = append(result, unicode.ToLower(r))
+			nextCharStartsWord = false
+		} else {
+			nextCharStartsWord = true
+		}
+		prev = r
+	}
+	return string(result)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func appendImports(imports []string, paths ...string) []string {
+	for _, path := range paths {
+		if !containsString(imports, path) {
+			imports = append(imports, path)
+		}
+	}
+	return imports
+}
+
+func writeImports(buf *bytes.Buffer, imports []string) {
+	switch len(imports) {
+	case 0:
+		return
+	case 1:
+		buf.WriteString(fmt.Sprintf("import \"%s\"\n\n", imports[0]))
+	default:
+		sort.Strings(imports)
+		buf.WriteString("import (\n")
+		for _, path := range imports {
+			buf.WriteString(fmt.Sprintf("\t\"%s\"\n", path))
+		}
+		buf.WriteString(")\n\n")
+	}
+}
+
+// convertToExportedIdentifier joins the words of s, which are separated by non alphanumeric runes
+// or start at an upper case letter following a lower case one, with their first letters in upper case.
+// Words matching one of forceCases case-insensitively are cased as given instead.
+//
+// Identifiers which would not start with an upper case letter get the prefix E: leading digits are
+// kept after it with the first following letter in upper case, so 3d_model and 3D_model both give
+// E3DModel and 2fa gives E2Fa, and a name without letters nor digits such as __ gives E. Leading
+// separators are dropped, so _hidden gives Hidden.
 func convertToExportedIdentifier(s string, forceCases []string) string {
 	var words []string
 	nextCharShouldBeUpperCase := true
+	// the first letter after leading digits is upper cased, whatever the case of the name
+	leadingDigits := false
+	var prev rune
 	for _, r := range s {
 		if unicode.IsLetter(r) || unicode.IsDigit(r) {
-			if nextCharShouldBeUpperCase {
+			if nextCharShouldBeUpperCase || (unicode.IsUpper(r) && unicode.IsLower(prev)) {
 				words = append(words, "")
 				words[len(words)-1] += string(unicode.ToUpper(r))
 				nextCharShouldBeUpperCase = false
+				leadingDigits = len(words) == 1 && unicode.IsDigit(r)
+			} else if leadingDigits && unicode.IsLetter(r) {
+				words[len(words)-1] += string(unicode.ToUpper(r))
+				leadingDigits = false
 			} else {
 				words[len(words)-1] += string(r)
 			}
 		} else {
 			nextCharShouldBeUpperCase = true
 		}
+		prev = r
 	}
 	result := ""
 	for _, word := range words {
@@ -128,179 +416,1082 @@ func convertToExportedIdentifier(s string, forceCases []string) string {
 		firstRune = r
 		break
 	}
-	if result == "" || !unicode.IsUpper(firstRune) {
+	if unicode.IsLower(firstRune) {
+		// a force cased word such as iOS starts the identifier
+		result = string(unicode.ToUpper(firstRune)) + result[utf8.RuneLen(firstRune):]
+	} else if result == "" || !unicode.IsUpper(firstRune) {
 		result = "E" + result
 	}
 	return result
 }
 
-func getType(fieldDescriptor fieldDescriptor) (goType string, err error) {
+var errUnknownFieldType = errors.New("unknown field type")
+
+// netTypes maps Postgres network types to Go types with -nettypes.
+var netTypes = map[string]string{
+	"inet":     "net.IP",
+	"cidr":     "net.IPNet",
+	"macaddr":  "net.HardwareAddr",
+	"macaddr8": "net.HardwareAddr",
+}
+
+var (
+	typeMappersMu sync.RWMutex
+	typeMappers   = make(map[string][]func(FieldDescriptor) (goType string, imports []string, ok bool))
+)
+
+// RegisterTypeMapper registers a function mapping field types of the given driver to Go types.
+// Registered mappers are consulted in order before the built-in mappings, the first one returning
+// ok wins. The pointer for nullable fields is added to the returned type automatically.
+func RegisterTypeMapper(driverName string, fn func(FieldDescriptor) (goType string, imports []string, ok bool)) {
+	typeMappersMu.Lock()
+	defer typeMappersMu.Unlock()
+	typeMappers[driverName] = append(typeMappers[driverName], fn)
+}
+
+func getRegisteredType(driverName string, fieldDescriptor FieldDescriptor) (goType string, imports []string, ok bool) {
+	typeMappersMu.RLock()
+	defer typeMappersMu.RUnlock()
+	for _, fn := range typeMappers[driverName] {
+		if goType, imports, ok = fn(fieldDescriptor); ok {
+			return
+		}
+	}
+	return
+}
+
+// trimTablePrefix strips the first of the prefixes tableName starts with.
+func trimTablePrefix(tableName string, prefixes []string) string {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(tableName, prefix) {
+			return strings.TrimPrefix(tableName, prefix)
+		}
+	}
+	return tableName
+}
+
+func getType(fieldDescriptor FieldDescriptor, options options) (goType string, imports []string, err error) {
+	if goType, imports, ok := getRegisteredType(options.driverName, fieldDescriptor); ok {
+		if fieldDescriptor.AllowNull || (options.allPointers && !isSliceType(goType)) {
+			goType = "*" + goType
+		}
+		return goType, imports, nil
+	}
+	if strings.HasSuffix(fieldDescriptor.Type, "[]") {
+		return getArrayType(fieldDescriptor, options)
+	}
+	// whether goType represents null itself, so nullable columns don't need a pointer
+	handlesNull := false
 	switch strings.ToLower(fieldDescriptor.Type) {
-	case "tinyint":
-		goType = "int8"
-	case "smallint":
+	case "tinyint", "int8":
+		if options.boolColumns && fieldDescriptor.Size == 1 {
+			goType = "bool"
+		} else {
+			goType = "int8"
+		}
+	case "smallint", "int16":
 		goType = "int16"
-	case "int", "mediumint":
+	case "int", "mediumint", "int32":
 		goType = "int32"
-	case "bigint", "integer":
+	case "bigint", "integer", "int64":
 		goType = "int64"
-	case "float", "double", "decimal", "real":
+	case "oid", "xid", "cid":
+		// Postgres object and transaction identifiers are unsigned 32-bit integers
+		goType = "uint32"
+	case "xid8":
+		goType = "uint64"
+	case "tid":
+		// tuple identifiers such as (0,1)
+		goType = "string"
+	case "float32":
+		goType = "float32"
+	case "float", "real":
+		if options.float32 && isSinglePrecision(fieldDescriptor.Type, options.driverName) {
+			goType = "float32"
+		} else {
+			goType = "float64"
+		}
+	case "double", "double precision", "float64":
 		goType = "float64"
-	case "char", "varchar", "text", "tinytext", "mediumtext", "longtext", "enum", "json", "numeric", "character varying":
+	case "decimal", "numeric", "decimal32", "decimal64", "decimal128":
+		switch {
+		case options.decimal:
+			// decimal.Decimal is signed, decimal unsigned columns use it too
+			goType = "decimal.Decimal"
+			imports = append(imports, "github.com/shopspring/decimal")
+		case strings.EqualFold(fieldDescriptor.Type, "numeric"):
+			goType = "string"
+		default:
+			goType = "float64"
+		}
+	case "money", "smallmoney":
+		// a string keeps the formatting of the currency, decimal.Decimal its exact amount
+		if options.decimal {
+			goType = "decimal.Decimal"
+			imports = append(imports, "github.com/shopspring/decimal")
+		} else {
+			goType = "string"
+		}
+	case "char", "varchar", "text", "tinytext", "mediumtext", "longtext", "enum", "set", "character varying",
+		"character", "bpchar", "name", "citext",
+		"nchar", "nvarchar", "ntext", "uniqueidentifier", "xml",
+		"string", "fixedstring", "uuid", "enum8", "enum16", "ipv4", "ipv6", "interval":
 		goType = "string"
-	case "datetime", "date", "time", "timestamp":
-		goType = "time.Time"
-	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob":
+	case "inet", "cidr", "macaddr", "macaddr8":
+		if options.netTypes {
+			goType = netTypes[strings.ToLower(fieldDescriptor.Type)]
+			imports = append(imports, "net")
+		} else {
+			goType = "string"
+		}
+	case "year":
+		if options.yearType == "string" {
+			goType = "string"
+		} else {
+			goType = "int16"
+		}
+	case "json", "jsonb":
+		if options.jsonType == "raw" {
+			goType = "json.RawMessage"
+			imports = append(imports, "encoding/json")
+		} else {
+			goType = "string"
+		}
+	case "date":
+		switch options.dateType {
+		case "civil":
+			goType = "civil.Date"
+			imports = append(imports, "cloud.google.com/go/civil")
+		case "string":
+			goType = "string"
+		default:
+			goType, imports = options.timeType.goType, append(imports, options.timeType.importPath)
+			handlesNull = options.timeType.handlesNull
+		}
+	case "datetime", "timestamp", "datetime2", "datetimeoffset", "smalldatetime", "date32", "datetime64",
+		"timestamp without time zone", "timestamp with time zone", "timestamptz":
+		goType, imports = options.timeType.goType, append(imports, options.timeType.importPath)
+		handlesNull = options.timeType.handlesNull
+	case "time", "time without time zone", "time with time zone", "timetz":
+		if options.timeOnly {
+			// times of day have no date, which time.Time would give as year 0
+			goType = "string"
+		} else {
+			goType, imports = options.timeType.goType, append(imports, options.timeType.importPath)
+			handlesNull = options.timeType.handlesNull
+		}
+	case "binary", "varbinary", "blob", "tinyblob", "mediumblob", "longblob", "image":
 		// TODO: use []byte ?
 		goType = "string"
 	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
 		goType = "sqlingo.WellKnownBinary"
+	case "bool":
+		goType = "bool"
 	case "bit":
-		if fieldDescriptor.Size == 1 {
+		switch {
+		case fieldDescriptor.Size == 1:
 			goType = "bool"
-		} else {
+		case options.driverName == "mysql" && fieldDescriptor.Size >= 2 && fieldDescriptor.Size <= 64:
+			// the MySQL driver returns bit fields as big-endian bytes, which cannot be scanned
+			// into integers
+			goType = "[]byte"
+		default:
+			// Postgres returns bit strings as text such as 00000101
 			goType = "string"
 		}
+	case "bit varying", "varbit":
+		goType = "string"
 	default:
-		err = fmt.Errorf("unknown field type %s", fieldDescriptor.Type)
+		err = fmt.Errorf("%w %s", errUnknownFieldType, fieldDescriptor.Type)
 		return
 	}
+	// Unsigned integers map to the unsigned Go type of the same width, since e.g. a bigint unsigned
+	// value above math.MaxInt64 would overflow int64. Floating point types have no unsigned
+	// counterpart and are left alone, as are decimals.
 	if fieldDescriptor.Unsigned && strings.HasPrefix(goType, "int") {
 		goType = "u" + goType
 	}
-	if fieldDescriptor.AllowNull {
+	if fieldDescriptor.AllowNull && !handlesNull && options.nullable == "sql" && !containsString(options.nullPointer, getTypeFamily(goType)) {
+		if nullType, ok := sqlNullTypes[goType]; ok {
+			if goType == "time.Time" {
+				imports = nil
+			}
+			goType, imports, handlesNull = nullType, append(imports, "database/sql"), true
+		}
+	}
+	if (fieldDescriptor.AllowNull && !handlesNull) || (options.allPointers && !isSliceType(goType)) {
 		goType = "*" + goType
 	}
 	return
 }
 
-func getTag(fieldDescriptor fieldDescriptor, options options) string {
-	var tagBuf bytes.Buffer
-	tagBuf.WriteString("`")
-	for i, tag := range options.tags {
-		if i != 0 {
-			tagBuf.WriteString(" ")
+// pqArrayTypes are the array types of github.com/lib/pq for the Go types of array elements.
+var pqArrayTypes = map[string]string{
+	"bool":    "pq.BoolArray",
+	"int32":   "pq.Int32Array",
+	"int64":   "pq.Int64Array",
+	"float32": "pq.Float32Array",
+	"float64": "pq.Float64Array",
+	"string":  "pq.StringArray",
+}
+
+// getArrayType returns the type of Postgres array columns such as character varying[]: the pq
+// array type of the element type, or a slice of it. Multidimensional arrays are flat slices too.
+// Nil slices are null, so nullable arrays are not pointers.
+func getArrayType(fieldDescriptor FieldDescriptor, options options) (goType string, imports []string, err error) {
+	element := fieldDescriptor
+	element.Type = strings.TrimRight(fieldDescriptor.Type, "[]")
+	element.AllowNull = false
+	// elements are never pointers
+	options.allPointers = false
+	if goType, imports, err = getType(element, options); err != nil {
+		return
+	}
+	if arrayType, ok := pqArrayTypes[goType]; ok {
+		return arrayType, append(imports, "github.com/lib/pq"), nil
+	}
+	return "[]" + goType, imports, nil
+}
+
+// sqlNullTypes are the types of database/sql for nullable columns with -nullable sql. Other types,
+// such as int8 or float32, are still pointers.
+var sqlNullTypes = map[string]string{
+	"bool":      "sql.NullBool",
+	"uint8":     "sql.NullByte",
+	"int16":     "sql.NullInt16",
+	"int32":     "sql.NullInt32",
+	"int64":     "sql.NullInt64",
+	"float64":   "sql.NullFloat64",
+	"string":    "sql.NullString",
+	"time.Time": "sql.NullTime",
+}
+
+// getTypeFamily returns the family of a Go type given to -nullpointer, such as int for int32.
+func getTypeFamily(goType string) string {
+	switch {
+	case strings.HasPrefix(goType, "int"):
+		return "int"
+	case strings.HasPrefix(goType, "uint"):
+		return "uint"
+	case strings.HasPrefix(goType, "float"):
+		return "float"
+	case goType == "time.Time":
+		return "time"
+	}
+	return goType
+}
+
+// isSliceType reports whether goType is a slice or map, including named ones such as net.IP, which
+// can already be nil so -allpointers doesn't make them pointers.
+func isSliceType(goType string) bool {
+	switch goType {
+	case "json.RawMessage", "net.IP", "net.HardwareAddr":
+		return true
+	}
+	if strings.HasPrefix(goType, "pq.") && strings.HasSuffix(goType, "Array") {
+		return true
+	}
+	return strings.HasPrefix(goType, "[]") || strings.HasPrefix(goType, "map[")
+}
+
+// isSinglePrecision reports whether a float or real column is single precision, which they are
+// except float of SQL Server and any floating point value of SQLite.
+func isSinglePrecision(fieldType, driverName string) bool {
+	switch {
+	case driverName == "sqlite3":
+		return false
+	case strings.EqualFold(fieldType, "float"):
+		return driverName != "sqlserver" && driverName != "mssql"
+	}
+	return strings.EqualFold(fieldType, "real")
+}
+
+// getOverriddenType returns the type given by -maptype for the column, along with the -imports
+// path whose package name qualifies the type. Overridden types are used as is, so they are only
+// pointers for nullable columns if the override says so.
+func getOverriddenType(tableName string, fieldDescriptor FieldDescriptor, options options) (goType string, imports []string, ok bool) {
+	goType, ok = options.typeOverrides[strings.ToLower(tableName+"."+fieldDescriptor.Name)]
+	if !ok {
+		return
+	}
+	qualifier := strings.TrimLeft(goType, "*[]")
+	if i := strings.Index(qualifier, "."); i >= 0 {
+		qualifier = qualifier[:i]
+		for _, path := range options.imports {
+			if path == qualifier || strings.HasSuffix(path, "/"+qualifier) {
+				imports = append(imports, path)
+				break
+			}
+		}
+	}
+	return
+}
+
+// isExcludedColumn reports whether the column of the table is given to -excludecolumns, either for the
+// table or for any table with *.
+func isExcludedColumn(tableName, columnName string, options options) bool {
+	columnName = strings.ToLower(columnName)
+	return options.excludedColumns[strings.ToLower(tableName)+"."+columnName] || options.excludedColumns["*."+columnName]
+}
+
+// getComment returns the column comment with the matches of -commenttrim removed, or nothing with
+// -nocomments.
+func getComment(comment string, options options) string {
+	if options.noComments {
+		return ""
+	}
+	if options.commentTrim != nil {
+		comment = strings.TrimSpace(options.commentTrim.ReplaceAllString(comment, ""))
+	}
+	return comment
+}
+
+// hasNoTableName reports whether the table matches a pattern of -notablename, leaving out its
+// TableName method as with -tablenamemode none.
+func hasNoTableName(tableName string, options options) bool {
+	for _, pattern := range options.noTableName {
+		if matched, _ := path.Match(pattern, tableName); matched {
+			return true
 		}
+	}
+	return false
+}
+
+func resolveType(tableName string, fieldDescriptor FieldDescriptor, options options) (goType string, imports []string, err error) {
+	if goType, imports, ok := getOverriddenType(tableName, fieldDescriptor, options); ok {
+		return goType, imports, nil
+	}
+	return getType(fieldDescriptor, options)
+}
+
+// tagOrder is the order of the tags in the struct tags, whatever the order given to -tag.
+var tagOrder = []string{"gorm", "json", "db", "pg", "validate"}
+
+// sortTags returns the tags without duplicates in the order of tagOrder, so the generated code
+// doesn't change with the order of -tag. Unknown tags, which generate nothing, come last.
+func sortTags(tags []string) []string {
+	var sorted []string
+	for _, tag := range tagOrder {
+		if containsString(tags, tag) {
+			sorted = append(sorted, tag)
+		}
+	}
+	for _, tag := range tags {
+		if !containsString(sorted, tag) {
+			sorted = append(sorted, tag)
+		}
+	}
+	return sorted
+}
+
+func getTag(fieldDescriptor FieldDescriptor, goType string, options options) string {
+	var tags []string
+	for _, tag := range options.tags {
 		switch tag {
 		case "gorm":
-			tagBuf.WriteString(fmt.Sprintf("gorm:\"column:%s\"", fieldDescriptor.Name))
+			gormTag := "column:" + fieldDescriptor.Name
+			if fieldDescriptor.PrimaryKeyOrdinal != 0 {
+				gormTag += ";primaryKey"
+			}
+			if fieldDescriptor.IsAutoIncrement {
+				gormTag += ";autoIncrement"
+			}
+			if fieldDescriptor.IsGenerated {
+				gormTag += ";->"
+			}
+			switch options.autoTimes[strings.ToLower(fieldDescriptor.Name)] {
+			case "create":
+				gormTag += ";autoCreateTime"
+			case "update":
+				gormTag += ";autoUpdateTime"
+			}
+			if hasGormDefault(fieldDescriptor, options) {
+				gormTag += ";default:" + *fieldDescriptor.DefaultValue
+			}
+			tags = append(tags, fmt.Sprintf("gorm:%q", gormTag))
 		case "json":
-			tagBuf.WriteString(fmt.Sprintf("json:\"%s\"", fieldDescriptor.Name))
+			tags = append(tags, fmt.Sprintf("json:\"%s\"", getJSONTag(fieldDescriptor, goType, options.jsonStringInts, options.omitEmpty)))
+		case "db":
+			tags = append(tags, fmt.Sprintf("db:\"%s\"", fieldDescriptor.Name))
 		case "pg":
-			tagBuf.WriteString(fmt.Sprintf("pg:\"%s\"", fieldDescriptor.Name))
+			pgTag := fieldDescriptor.Name
+			if fieldDescriptor.PrimaryKeyOrdinal != 0 {
+				pgTag += ",pk"
+			}
+			tags = append(tags, fmt.Sprintf("pg:\"%s\"", pgTag))
+		case "validate":
+			if rules := getValidateRules(fieldDescriptor, goType); len(rules) != 0 {
+				tags = append(tags, fmt.Sprintf("validate:\"%s\"", strings.Join(rules, ",")))
+			}
 		}
 	}
-	tagBuf.WriteString("`")
-	return tagBuf.String()
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// hasDefault reports whether the column has a default worth generating. The values of auto-increment
+// columns, such as the nextval of the sequence of Postgres serial columns, are not defaults to give.
+func hasDefault(fieldDescriptor FieldDescriptor) bool {
+	return fieldDescriptor.DefaultValue != nil && !fieldDescriptor.IsAutoIncrement &&
+		!strings.HasPrefix(strings.ToLower(*fieldDescriptor.DefaultValue), "nextval(")
+}
+
+// hasGormDefault reports whether the default of the column is given in its gorm tag instead of a
+// comment. Defaults which cannot be written in a struct tag are left to the comment.
+func hasGormDefault(fieldDescriptor FieldDescriptor, options options) bool {
+	return hasDefault(fieldDescriptor) && containsString(options.tags, "gorm") && !strings.ContainsAny(*fieldDescriptor.DefaultValue, "`;")
+}
+
+func getValidateRules(fieldDescriptor FieldDescriptor, goType string) (rules []string) {
+	if !fieldDescriptor.AllowNull {
+		rules = append(rules, "required")
+	}
+	if strings.TrimPrefix(goType, "*") == "string" && fieldDescriptor.Size > 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", fieldDescriptor.Size))
+	}
+	return
+}
+
+// quoteTableName quotes a table name, quoting the schema and the name separately for schema
+// qualified Postgres tables.
+func quoteTableName(schemaFetcher SchemaFetcher, tableName string, options options) string {
+	if schema, name, ok := strings.Cut(tableName, "."); ok && len(options.schemas) != 0 {
+		return schemaFetcher.QuoteIdentifier(schema) + "." + schemaFetcher.QuoteIdentifier(name)
+	}
+	return schemaFetcher.QuoteIdentifier(tableName)
+}
+
+// getStructName returns the name of the struct generated for the table.
+func getStructName(tableName string, options options) string {
+	structName := tableName
+	if _, name, ok := strings.Cut(tableName, "."); ok && len(options.schemas) != 0 && !options.ambiguousTableNames[name] {
+		// schema qualified Postgres table, only keep the schema if the name exists in several schemas
+		structName = name
+	}
+	structName = trimTablePrefix(structName, options.trimPrefixes)
+	if options.singularize {
+		structName = singularize(structName)
+	}
+	return options.structPrefix + convertToExportedIdentifier(structName, options.forceCases) + options.structSuffix
 }
 
-func generateTable(schemaFetcher schemaFetcher, dbName, tableName string, options options) error {
+func generateTable(schemaFetcher SchemaFetcher, dbName, tableName string, options options) error {
 	fieldDescriptors, err := schemaFetcher.GetFieldDescriptors(tableName)
 	if err != nil {
 		return err
 	}
 
-	className := convertToExportedIdentifier(tableName, options.forceCases)
+	className := getStructName(tableName, options)
+
+	// numbers of the columns in schema order, used by -proto whatever -fieldorder is
+	columnNumbers := make(map[string]int)
+	for i, fieldDescriptor := range fieldDescriptors {
+		columnNumbers[fieldDescriptor.Name] = i + 1
+	}
+	if len(options.excludedColumns) != 0 {
+		var kept []FieldDescriptor
+		for _, fieldDescriptor := range fieldDescriptors {
+			if !isExcludedColumn(tableName, fieldDescriptor.Name, options) {
+				kept = append(kept, fieldDescriptor)
+			}
+		}
+		fieldDescriptors = kept
+	}
+	if options.fieldOrder == "alpha" {
+		sort.SliceStable(fieldDescriptors, func(i, j int) bool {
+			return strings.ToLower(fieldDescriptors[i].Name) < strings.ToLower(fieldDescriptors[j].Name)
+		})
+	}
+
+	var (
+		embedded      = options.embedded
+		isEmbedded    bool
+		isFirstEmbed  bool
+		embeddedTypes = make(map[string]string)
+	)
+	if embedded != nil {
+		for _, fieldDescriptor := range fieldDescriptors {
+			if goType, _, err := resolveType(tableName, fieldDescriptor, options); err == nil {
+				embeddedTypes[fieldDescriptor.Name] = goType
+			}
+		}
+		isEmbedded = embedded.embeds(tableName, embeddedTypes, options.logger)
+		isFirstEmbed = isEmbedded && embedded.goTypes == nil
+		if isFirstEmbed {
+			embedded.goTypes = embeddedTypes
+		}
+	}
 
 	var (
-		modeLinesBuf     bytes.Buffer
-		isNeedImportTime bool
+		imports   []string
+		enumTypes []enumTypeDef
+		// all the columns, including the promoted ones of the embedded struct
+		columnFields []modelField
+		// the resolved fields of all the columns, rendered as the struct or by -template
+		fieldInfos []FieldInfo
+		// notes of the columns left out by -onunknown skip
+		skippedColumns []string
 	)
 	for _, fieldDescriptor := range fieldDescriptors {
+		fieldDescriptor.Comment = getComment(fieldDescriptor.Comment, options)
 		goName := convertToExportedIdentifier(fieldDescriptor.Name, options.forceCases)
-		goType, err := getType(fieldDescriptor)
+		goType, fieldImports, err := resolveType(tableName, fieldDescriptor, options)
+		var unknownTypeNote string
+		if errors.Is(err, errUnknownFieldType) && options.onUnknown != "error" {
+			if options.onUnknown == "skip" {
+				options.logger.Printf("warning: skip %s.%s: %s", tableName, fieldDescriptor.Name, err)
+				skippedColumns = append(skippedColumns, fmt.Sprintf("%s: %s", fieldDescriptor.Name, err))
+				continue
+			}
+			unknownTypeNote = fmt.Sprintf("%s: %s", fieldDescriptor.Name, err)
+			goType, fieldImports, err = options.emptyInterface, nil, nil
+		}
+		if err != nil {
+			return err
+		}
+		if options.enums && len(fieldDescriptor.EnumValues) != 0 && strings.TrimPrefix(goType, "*") == "string" {
+			enumType := className + goName
+			enumTypes = append(enumTypes, enumTypeDef{name: enumType, values: fieldDescriptor.EnumValues})
+			if strings.EqualFold(fieldDescriptor.Type, "enum") {
+				// set values combine several of the constants, so only enum fields use the type
+				goType = strings.Replace(goType, "string", enumType, 1)
+			}
+		}
+		logFieldType(tableName, fieldDescriptor, goType, options)
+		fieldInfo := newFieldInfo(fieldDescriptor, goName, goType, fieldImports, options)
+		if unknownTypeNote != "" {
+			fieldInfo.Comments = append([]string{unknownTypeNote}, fieldInfo.Comments...)
+		}
+		fieldInfo.Embedded = isEmbedded && containsString(embedded.columns, fieldDescriptor.Name)
+		fieldInfos = append(fieldInfos, fieldInfo)
+		columnFields = append(columnFields, modelField{name: goName, goType: goType, fieldDescriptor: fieldDescriptor, imports: fieldImports})
+	}
+	var relationFields []FieldInfo
+	if foreignKeyFetcher, ok := schemaFetcher.(ForeignKeyFetcher); ok && options.relations {
+		foreignKeys, err := foreignKeyFetcher.GetForeignKeys(tableName)
 		if err != nil {
 			return err
 		}
+		relationFields = getRelationFields(tableName, columnFields, foreignKeys, options)
+	}
+
+	for _, fieldInfo := range fieldInfos {
+		if !fieldInfo.Embedded {
+			imports = appendImports(imports, fieldInfo.Imports...)
+		} else if isFirstEmbed {
+			embedded.fields = append(embedded.fields, fieldInfo)
+		}
+	}
 
-		commentLine := ""
-		if fieldDescriptor.Comment != "" {
-			commentLine = "\t// " + strings.ReplaceAll(fieldDescriptor.Comment, "\n", " ") + "\n"
+	packageName, fileName := dbName, getFileName(tableName, className, options.fileCase)
+	if options.perPackage {
+		if packageName, fileName, err = getTablePackage(tableName, fileName, options); err != nil {
+			return err
 		}
+	}
+
+	if options.manifest != nil {
+		options.manifest.add(tableName, className, fieldInfos)
+	}
+	if options.proto != nil {
+		options.proto.addMessage(className, columnFields, columnNumbers)
+	}
+	if options.registry != nil {
+		options.registry.add(tableName, className, columnFields)
+	}
+
+	var methods bytes.Buffer
+	var methodImports []string
+	for _, enumType := range enumTypes {
+		writeEnumType(&methods, enumType, options)
+		methodImports = appendImports(methodImports, "fmt")
+	}
+
+	tableNameMode := options.tableNameMode
+	if hasNoTableName(tableName, options) {
+		tableNameMode = "none"
+	}
+	switch tableNameMode {
+	case "value":
+		methods.WriteString(fmt.Sprintf("func (m %s) %s() string {\n", className, options.tableNameFunc))
+	case "pointer":
+		methods.WriteString(fmt.Sprintf("func (m *%s) %s() string {\n", className, options.tableNameFunc))
+	}
+	if tableNameMode != "none" {
+		methods.WriteString(fmt.Sprintf("\treturn \"%s\"\n", tableName))
+		methods.WriteString("}\n\n")
+	}
 
-		modeLinesBuf.WriteString(commentLine)
-		modeLinesBuf.WriteString(fmt.Sprintf("\t%s %s %s\n", goName, goType, getTag(fieldDescriptor, options)))
-		switch fieldDescriptor.Type {
-		case "datetime", "date", "time", "timestamp":
-			isNeedImportTime = true
+	if options.quoteIdentifiers {
+		methods.WriteString("// QuotedTableName returns the table name quoted as an identifier.\n")
+		methods.WriteString(fmt.Sprintf("func (m %s) QuotedTableName() string {\n", className))
+		methods.WriteString(fmt.Sprintf("\treturn %q\n", quoteTableName(schemaFetcher, tableName, options)))
+		methods.WriteString("}\n\n")
+	}
+	if options.sqlHelpers {
+		writeSQLHelpers(&methods, className, columnFields, options.emptyInterface)
+		if options.quoteIdentifiers {
+			writeQuotedColumns(&methods, className, columnFields, schemaFetcher)
+		}
+	}
+	if options.fieldMap {
+		writeFieldMap(&methods, className, columnFields)
+	}
+	if options.constructors {
+		methodImports = appendImports(methodImports, writeConstructor(&methods, className, columnFields)...)
+	}
+	if options.jsonMethods {
+		methodImports = appendImports(methodImports, writeJSONMethods(&methods, className, columnFields, options.jsonStringInts, options.omitEmpty)...)
+		methodImports = appendImports(methodImports, "encoding/json")
+	}
+	if options.diffMethods && writeEqualMethod(&methods, className, columnFields) {
+		methodImports = appendImports(methodImports, "bytes")
+	}
+	if options.stringer {
+		if writeStringMethod(&methods, className, columnFields) {
+			methodImports = appendImports(methodImports, "fmt")
 		}
+		methodImports = appendImports(methodImports, "strings")
+	}
+	if options.orm == "sqlingo" {
+		writeSqlingoModel(&methods, tableName, className, columnFields, options.emptyInterface)
+		methodImports = appendImports(methodImports, sqlingoImportPath)
 	}
 
-	var buf = newBuffWithBaseHeader(dbName)
-	if isNeedImportTime {
-		buf.WriteString("import \"time\"\n\n")
+	data := TemplateData{
+		Header:         getFileHeader(options),
+		PackageName:    ensureIdentifier(packageName),
+		StructName:     className,
+		TableName:      tableName,
+		IsView:         options.viewNames[tableName],
+		Fields:         fieldInfos,
+		SkippedColumns: skippedColumns,
+		Relations:      relationFields,
+		Imports:        imports,
+		Methods:        methods.String(),
+		MethodImports:  methodImports,
+	}
+	if isEmbedded {
+		data.Embedded = embedded.name
 	}
-	buf.WriteString(fmt.Sprintf("type %s struct {\n", className))
-	buf.WriteString(modeLinesBuf.String())
-	buf.WriteString("}\n\n")
+	if options.stdout != nil {
+		// the single file of -stdout has a header and imports of its own
+		var buf bytes.Buffer
+		if err = options.template.ExecuteTemplate(&buf, "model", data); err != nil {
+			return fmt.Errorf("%s: %w", tableName, err)
+		}
+		return writeSource(packageName, tableName, fileName, data.FileImports(), buf.Bytes(), options)
+	}
+	return writeTemplateSource(fileName, data, options)
+}
 
-	buf.WriteString(fmt.Sprintf("func (m %s) TableName() string {\n", className))
-	buf.WriteString(fmt.Sprintf("\treturn \"%s\"\n", tableName))
-	buf.WriteString("}\n\n")
-	err = writeToFile(buf, fmt.Sprintf("%s/%s.go", *outputPath, tableName), true)
-	return err
+// getTablePackage returns the package of a table with -perpackage, named after the table, and the
+// name of its file in the directory of the package, which is created.
+func getTablePackage(tableName, fileName string, options options) (packageName, packageFileName string, err error) {
+	packageName = strings.ToLower(ensureIdentifier(tableName))
+	if token.IsKeyword(packageName) {
+		packageName += "_"
+	}
+	if !options.dryRun {
+		if err = os.MkdirAll(filepath.Join(options.outputPath, fileName), 0755); err != nil {
+			return
+		}
+	}
+	return packageName, fileName + "/" + fileName, nil
 }
 
+// dataSourceNameEnv is the environment variable giving the database connection if -dbc is not set,
+// which keeps passwords out of the shell history and process list.
+const dataSourceNameEnv = "SQLMODEL_DSN"
+
 var (
-	outputPath         = flag.String("o", "", "file output path")
-	databaseConnection = flag.String("dbc", "", "database connection")
-	tables             = flag.String("t", "", "-t table1,table2,...")
-	tag                = flag.String("tag", "", "-tag gorm,json,pg")
-	forcecases         = flag.String("forcecases", "", "-forcecases ID,IDs,HTML")
+	configFile = flag.String("config", "", "-config sqlmodel.json, flags override its values")
+	flagConfig Config
 )
 
-// Generate generates code for the given driverName.
+func init() {
+	flag.StringVar(&flagConfig.Output, "o", "", "file output path")
+	flag.StringVar(&flagConfig.Package, "package", "", "-package models is the name of the generated package instead of the database name")
+	flag.Var(dataSourceNamesFlag{&flagConfig}, "dbc", "database connection, defaults to $"+dataSourceNameEnv+", may be repeated to generate databases into subdirectories")
+	flag.StringVar(&flagConfig.Overwrite, "overwrite", "prompt", "-overwrite always|never|prompt is whether existing files are overwritten, prompt asks and is never without a terminal")
+	flag.BoolVar(&flagConfig.Changed, "changed", false, "-changed only writes the files which differ from the existing ones and prints them")
+	flag.StringVar(&flagConfig.TLSCA, "tlsca", "", "-tlsca ca.pem verifies the server certificate with the CA for mysql and postgres")
+	flag.StringVar(&flagConfig.TLSCert, "tlscert", "", "-tlscert client-cert.pem is the client certificate, with -tlskey")
+	flag.StringVar(&flagConfig.TLSKey, "tlskey", "", "-tlskey client-key.pem is the key of the client certificate")
+	flag.StringVar(&flagConfig.TLSServerName, "tlsservername", "", "-tlsservername verifies the server certificate for the name, for mysql")
+	flag.BoolVar(&flagConfig.DryRun, "dryrun", false, "-dryrun prints the files which would be written instead of writing them")
+	flag.StringVar(&flagConfig.Manifest, "manifest", "", "-manifest models.json also writes the generated tables and their fields as JSON")
+	flag.BoolVar(&flagConfig.Registry, "registry", false, "-registry also generates a Tables map from table names to their column metadata")
+	flag.StringVar(&flagConfig.Marker, "marker", "", "-marker '// Code generated by tool; DO NOT EDIT.' is the line marking the generated files")
+	flag.BoolVar(&flagConfig.GoGenerate, "gogenerate", false, "-gogenerate writes a //go:generate directive running the command again, with the data source name of $"+dataSourceNameEnv)
+	flag.BoolVar(&flagConfig.GitAttributes, "gitattributes", false, "-gitattributes marks the generated files as generated in the .gitattributes of the output path")
+	flag.StringVar(&flagConfig.Template, "template", "", "-template model.tmpl renders the file of each table with the text/template instead")
+	flag.StringVar(&flagConfig.Proto, "proto", "", "-proto models.proto also writes a .proto file with a message per table")
+	flag.BoolVar(&flagConfig.PerPackage, "perpackage", false, "-perpackage writes each table to its own package in a directory of -o named after it")
+	flag.BoolVar(&flagConfig.Stdout, "stdout", false, "-stdout writes the generated code to stdout as a single file instead of -o")
+	flag.Var((*stringsFlag)(&flagConfig.Tables), "t", "-t table1,table2,...")
+	flag.IntVar(&flagConfig.MaxTables, "maxtables", 0, "-maxtables 100 fails if the database has more tables, unless they are given by -t")
+	flag.StringVar(&flagConfig.TablesFile, "tablesfile", "", "-tablesfile tables.txt adds the tables of the file, one per line, to -t")
+	flag.Var((*stringsFlag)(&flagConfig.Tags), "tag", "-tag gorm,json,db,pg,validate")
+	flag.Var((*stringsFlag)(&flagConfig.ForceCases), "forcecases", "-forcecases ID,IDs,HTML")
+	flag.StringVar(&flagConfig.TimeType, "timetype", "time.Time", "-timetype github.com/myorg/types.Time is the type of date and time columns")
+	flag.StringVar(&flagConfig.Nullable, "nullable", "pointer", "-nullable pointer|sql is the type of nullable columns, sql uses the sql.Null types where there is one")
+	flag.Var((*stringsFlag)(&flagConfig.NullPointer), "nullpointer", "-nullpointer string,time leaves nullable columns of these types pointers and implies -nullable sql for the others")
+	flag.BoolVar(&flagConfig.Decimal, "decimal", false, "-decimal maps decimal, numeric and money columns to decimal.Decimal of github.com/shopspring/decimal")
+	flag.BoolVar(&flagConfig.TimeOnly, "timeonly", false, "-timeonly maps time of day columns to string instead of the time type")
+	flag.StringVar(&flagConfig.DateType, "datetype", "time", "-datetype time|civil|string")
+	flag.StringVar(&flagConfig.YearType, "yeartype", "int16", "-yeartype int16|string")
+	flag.StringVar(&flagConfig.JSONType, "jsontype", "string", "-jsontype raw|string")
+	flag.Var((*stringsFlag)(&flagConfig.TrimPrefixes), "trimprefix", "-trimprefix t_,tbl_")
+	flag.StringVar(&flagConfig.StructPrefix, "structprefix", "", "-structprefix Model")
+	flag.StringVar(&flagConfig.StructSuffix, "structsuffix", "", "-structsuffix Model")
+	flag.BoolVar(&flagConfig.Singularize, "singularize", false, "-singularize singularizes table names for struct names")
+	flag.Var((*stringsFlag)(&flagConfig.Schemas), "schema", "-schema public,app (postgres only)")
+	flag.StringVar(&flagConfig.Embed, "embed", "", "-embed Base:id,created_at,updated_at")
+	flag.BoolVar(&flagConfig.Relations, "relations", false, "-relations generates belongs-to fields with gorm association tags from foreign keys")
+	flag.BoolVar(&flagConfig.Views, "views", false, "-views generates models for views too")
+	flag.Var((*stringsFlag)(&flagConfig.NoTableName), "notablename", "-notablename v_*,report leaves out the TableName method of the matching tables and views")
+	flag.StringVar(&flagConfig.TableNameMode, "tablenamemode", "value", "-tablenamemode value|pointer|none is the receiver of the TableName method, none leaves it out")
+	flag.StringVar(&flagConfig.TableNameFunc, "tablenamefunc", "TableName", "-tablenamefunc is the name of the TableName method")
+	flag.StringVar(&flagConfig.FieldOrder, "fieldorder", "schema", "-fieldorder schema|alpha orders the fields as the columns of the table or alphabetically")
+	flag.StringVar(&flagConfig.FileCase, "filecase", "table", "-filecase snake|struct|table")
+	flag.BoolVar(&flagConfig.Enums, "enums", false, "-enums generates types and constants for mysql enum and set columns")
+	flag.BoolVar(&flagConfig.NetTypes, "nettypes", false, "-nettypes maps postgres inet, cidr and macaddr to net types")
+	flag.BoolVar(&flagConfig.AllPointers, "allpointers", false, "-allpointers makes every field a pointer, including not null columns, for partial updates")
+	flag.BoolVar(&flagConfig.Float32, "float32", false, "-float32 maps single precision float and real columns to float32")
+	flag.BoolVar(&flagConfig.BoolColumns, "boolcolumns", false, "-boolcolumns maps tinyint(1) to bool")
+	flag.StringVar(&flagConfig.Header, "header", "", "-header header.txt")
+	flag.BoolVar(&flagConfig.HeaderReplace, "header-replace", false, "-header-replace replaces the generated header with the -header file")
+	flag.StringVar(&flagConfig.BuildTags, "buildtags", "", "-buildtags 'generated && !test'")
+	flag.StringVar(&flagConfig.CommentTrim, "commenttrim", "", "-commenttrim '@deprecated;?' removes the matches of the regular expression from column comments")
+	flag.BoolVar(&flagConfig.NoComments, "nocomments", false, "-nocomments leaves out the column comments and the comments of types and defaults")
+	flag.BoolVar(&flagConfig.PreserveNames, "preservenames", false, "-preservenames comments column names of renamed fields")
+	flag.BoolVar(&flagConfig.QuoteIdentifiers, "quoteidentifiers", false, "-quoteidentifiers generates a QuotedTableName method and, with -sqlhelpers, the quoted columns")
+	flag.BoolVar(&flagConfig.SQLHelpers, "sqlhelpers", false, "-sqlhelpers generates Columns variables and Values methods")
+	flag.BoolVar(&flagConfig.FieldMap, "fieldmap", false, "-fieldmap generates FieldColumns variables mapping the field names to the columns")
+	flag.BoolVar(&flagConfig.Constructors, "constructors", false, "-constructors generates a New function taking the not null columns without default")
+	flag.BoolVar(&flagConfig.DiffMethods, "diffmethods", false, "-diffmethods generates an Equal method comparing the fields")
+	flag.BoolVar(&flagConfig.JSONStringInts, "jsonstringints", false, "-jsonstringints adds the string option to the json tags of 64-bit integers")
+	flag.BoolVar(&flagConfig.OmitEmpty, "omitempty", false, "-omitempty adds the omitempty option to the json tags of nullable columns")
+	flag.BoolVar(&flagConfig.JSONMethods, "jsonmethods", false, "-jsonmethods generates MarshalJSON and UnmarshalJSON methods mapping the columns to JSON keys")
+	flag.StringVar(&flagConfig.ORM, "orm", "", "-orm sqlingo also generates the sqlingo table of each model")
+	flag.BoolVar(&flagConfig.Stringer, "stringer", false, "-stringer generates String methods")
+	flag.IntVar(&flagConfig.Retry, "retry", 0, "-retry 5 retries connecting to the database")
+	flag.StringVar(&flagConfig.RetryInterval, "retryinterval", "1s", "-retryinterval 1s is the interval before the first retry, doubled for each next one")
+	flag.BoolVar(&flagConfig.KeepGoing, "keepgoing", false, "-keepgoing generates the other tables when one fails")
+	flag.BoolVar(&flagConfig.Strict, "strict", false, "-strict fails when the database has no tables instead of warning")
+	flag.BoolVar(&flagConfig.Verbose, "v", false, "-v logs the queries and the type of each field")
+	flag.BoolVar(&flagConfig.Quiet, "q", false, "-q does not log the table being generated")
+	flag.StringVar(&flagConfig.EmptyInterface, "emptyinterface", "any", "-emptyinterface any|interface{} is how the empty interface is spelled")
+	flag.StringVar(&flagConfig.OnUnknown, "onunknown", "error", "-onunknown error|skip|any")
+	flag.Var((*stringsFlag)(&flagConfig.AutoTimes), "autotime", "-autotime created_at:create,updated_at:update lets gorm set the time of the columns")
+	flag.Var((*stringsFlag)(&flagConfig.MapTypes), "maptype", "-maptype table.column=GoType,... (may be repeated)")
+	flag.Var((*stringsFlag)(&flagConfig.ExcludeColumns), "excludecolumns", "-excludecolumns table.column,*.column leaves the columns out of the models (may be repeated)")
+	flag.Var((*stringsFlag)(&flagConfig.Imports), "imports", "-imports path1,path2,... imports for types given by -maptype (may be repeated)")
+}
+
+// getDatabaseName gets the database name, which is the first query sent to the database, retrying
+// with backoff for databases still starting up.
+func getDatabaseName(schemaFetcher SchemaFetcher, options options) (dbName string, err error) {
+	interval := options.retryInterval
+	for attempt := 0; ; attempt++ {
+		dbName, err = schemaFetcher.GetDatabaseName()
+		if err == nil || attempt >= options.retry {
+			return
+		}
+		options.logger.Printf("warning: %s, retrying in %s", err, interval)
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// pingDatabase checks the connection to db, retrying as getDatabaseName, so wrong credentials or
+// addresses fail before the generation.
+func pingDatabase(db *sql.DB, options options) (err error) {
+	interval := options.retryInterval
+	for attempt := 0; ; attempt++ {
+		err = db.Ping()
+		if err == nil || attempt >= options.retry {
+			return
+		}
+		options.logger.Printf("warning: %s, retrying in %s", err, interval)
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// ListTables returns the names of the tables in the database of the given driverName.
+func ListTables(driverName string, dataSourceName string) ([]string, error) {
+	schemaFetcherFactory, err := getSchemaFetcherFactory(driverName)
+	if err != nil {
+		return nil, err
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return schemaFetcherFactory(db, options{}).GetTableNames()
+}
+
+// Generate generates code for the given driverName, configured by the command line flags. If
+// driverName is empty or auto, it is inferred from the data source name.
 func Generate(driverName string, exampleDataSourceName string) error {
 	flag.Parse()
-	if len(*outputPath) == 0 {
+	var config Config
+	if len(*configFile) != 0 {
+		var err error
+		if config, err = loadConfigFile(*configFile); err != nil {
+			return err
+		}
+	}
+	mergeFlags(&config, &flagConfig)
+	setDataSourceNameFromEnv(&config)
+	if len(config.Output) == 0 && !config.Stdout {
 		printUsageAndExit(exampleDataSourceName)
 	}
-	if len(*databaseConnection) == 0 {
+	if len(config.DataSourceName) == 0 && len(config.DataSourceNames) == 0 {
 		printUsageAndExit(exampleDataSourceName)
 	}
-	var options options
-	options.dataSourceName = *databaseConnection
-	if len(*tables) != 0 {
-		options.tableNames = strings.Split(*tables, ",")
+	err := GenerateWithConfig(driverName, config)
+	if config.Strict {
+		return err
+	}
+	// databases without tables are already warned about
+	var dataSourceErrors DataSourceErrors
+	if errors.As(err, &dataSourceErrors) {
+		var failed DataSourceErrors
+		for _, dataSourceError := range dataSourceErrors {
+			if !errors.Is(dataSourceError, ErrNoTables) {
+				failed = append(failed, dataSourceError)
+			}
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+		return failed
+	}
+	if errors.Is(err, ErrNoTables) {
+		return nil
+	}
+	return err
+}
+
+// GenerateWithConfig generates code for the given driverName, configured by config. If driverName
+// is empty or auto, it is inferred from config.DataSourceName. With config.DataSourceNames, each
+// database is generated into a subdirectory of the output path named after it, and the errors of
+// the databases are returned as DataSourceErrors.
+func GenerateWithConfig(driverName string, config Config) error {
+	var dataSourceNames []string
+	if config.DataSourceName != "" {
+		dataSourceNames = append(dataSourceNames, config.DataSourceName)
+	}
+	dataSourceNames = append(dataSourceNames, config.DataSourceNames...)
+	if len(dataSourceNames) <= 1 {
+		if len(dataSourceNames) == 1 {
+			config.DataSourceName = dataSourceNames[0]
+		}
+		config.DataSourceNames = nil
+		return generateDataSource(driverName, config, false)
+	}
+	if config.Stdout {
+		return errors.New("several data source names are generated into subdirectories, they cannot be combined with -stdout")
+	}
+
+	// all the data source names must be of the same driver, check them before generating any
+	driverNames := make([]string, len(dataSourceNames))
+	for i, dataSourceName := range dataSourceNames {
+		driverNames[i] = driverName
+		if driverName == "" || driverName == "auto" {
+			var err error
+			if driverNames[i], dataSourceNames[i], err = inferDriver(dataSourceName); err != nil {
+				return err
+			}
+		}
+		if driverNames[i] != driverNames[0] {
+			return fmt.Errorf("data source names of different drivers: %s is %s, %s is %s", redactDataSourceName(dataSourceNames[0]), driverNames[0], redactDataSourceName(dataSourceName), driverNames[i])
+		}
+	}
+	var dataSourceErrors DataSourceErrors
+	for i, dataSourceName := range dataSourceNames {
+		dataSourceConfig := config
+		dataSourceConfig.DataSourceName, dataSourceConfig.DataSourceNames = dataSourceName, nil
+		if err := generateDataSource(driverNames[i], dataSourceConfig, true); err != nil {
+			dataSourceErrors = append(dataSourceErrors, DataSourceError{DataSourceName: redactDataSourceName(dataSourceName), Err: err})
+		}
+	}
+	if len(dataSourceErrors) != 0 {
+		return dataSourceErrors
+	}
+	return nil
+}
+
+// generateDataSource generates code for a single data source name, into the subdirectory named
+// after its database if dbSubdirectory is set.
+func generateDataSource(driverName string, config Config, dbSubdirectory bool) error {
+	if driverName == "" || driverName == "auto" {
+		var err error
+		if driverName, config.DataSourceName, err = inferDriver(config.DataSourceName); err != nil {
+			return err
+		}
+	}
+	schemaFetcherFactory, err := getSchemaFetcherFactory(driverName)
+	if err != nil {
+		return err
 	}
-	if len(*tag) != 0 {
-		options.tags = strings.Split(*tag, ",")
+	options, err := newOptions(driverName, config)
+	if err != nil {
+		return err
 	}
-	if len(*forcecases) != 0 {
-		options.forceCases = strings.Split(*forcecases, ",")
+	options.dbSubdirectory = dbSubdirectory
+	dataSourceName, err := applyTLS(driverName, options.dataSourceName, options.tls)
+	if err != nil {
+		return err
 	}
 
-	db, err := sql.Open(driverName, options.dataSourceName)
+	db, err := sql.Open(driverName, dataSourceName)
 	if err != nil {
 		return err
 	}
+	if err = pingDatabase(db, options); err != nil {
+		return fmt.Errorf("failed to connect to %s database %s: %w", driverName, redactDataSourceName(options.dataSourceName), err)
+	}
+
+	return generate(schemaFetcherFactory(db, options), options)
+}
 
-	schemaFetcherFactory := getSchemaFetcherFactory(driverName)
-	schemaFetcher := schemaFetcherFactory(db)
+// GenerateWithFetcher generates code for the schema given by schemaFetcher, configured by config
+// whose DataSourceName is unused. It allows generating from databases without a built-in driver.
+func GenerateWithFetcher(schemaFetcher SchemaFetcher, config Config) error {
+	options, err := newOptions("", config)
+	if err != nil {
+		return err
+	}
+	return generate(schemaFetcher, options)
+}
 
-	dbName, err := schemaFetcher.GetDatabaseName()
+func generate(schemaFetcher SchemaFetcher, options options) error {
+	dbName, err := getDatabaseName(schemaFetcher, options)
 	if err != nil {
 		return err
 	}
 
+	if options.dbSubdirectory {
+		options.outputPath = filepath.Join(options.outputPath, ensureIdentifier(dbName))
+		if !options.dryRun && options.writerFor == nil {
+			if err = os.MkdirAll(options.outputPath, 0755); err != nil {
+				return err
+			}
+		}
+	}
+	if options.packageName != "" {
+		// the database name is only the name of the generated package
+		dbName = options.packageName
+	}
 	if dbName == "" {
-		return errors.New("no database selected")
+		return errors.New("no database selected, give the package name with -package")
 	}
 
-	if len(options.tableNames) == 0 {
+	isTableNamesGiven := len(options.tableNames) != 0
+	if !isTableNamesGiven {
 		options.tableNames, err = schemaFetcher.GetTableNames()
 		if err != nil {
 			return err
 		}
 	}
+	if options.views {
+		viewNames, err := schemaFetcher.GetViewNames()
+		if err != nil {
+			return err
+		}
+		options.viewNames = make(map[string]bool)
+		for _, viewName := range viewNames {
+			options.viewNames[viewName] = true
+		}
+		if !isTableNamesGiven {
+			options.tableNames = append(options.tableNames, viewNames...)
+		}
+	}
+	if len(options.tableNames) == 0 {
+		options.logger.Printf("warning: no tables found in database %s", dbName)
+		return fmt.Errorf("%w in database %s", ErrNoTables, dbName)
+	}
+	if !isTableNamesGiven && options.maxTables != 0 && len(options.tableNames) > options.maxTables {
+		return fmt.Errorf("found %d tables in database %s, more than -maxtables %d, give them with -t", len(options.tableNames), dbName, options.maxTables)
+	}
+	if !isTableNamesGiven {
+		// the order of discovered tables depends on the database, sort them for reproducible output
+		sort.Strings(options.tableNames)
+	}
+
+	if len(options.schemas) != 0 {
+		options.ambiguousTableNames = make(map[string]bool)
+		seen := make(map[string]bool)
+		for _, tableName := range options.tableNames {
+			_, name := splitPostgresTableName(tableName)
+			if seen[name] {
+				options.ambiguousTableNames[name] = true
+			}
+			seen[name] = true
+		}
+	}
 
-	for _, tableName := range options.tableNames {
-		println("Generating", tableName)
+	if _, ok := schemaFetcher.(ForeignKeyFetcher); options.relations && !ok {
+		options.logger.Printf("warning: -relations is not supported by the schema fetcher, no relation fields are generated")
+	}
+
+	var tableErrors TableErrors
+	for i, tableName := range options.tableNames {
+		if !options.quiet {
+			options.logger.Printf("Generating %s (%d/%d)", tableName, i+1, len(options.tableNames))
+		}
 		err = generateTable(schemaFetcher, dbName, tableName, options)
+		if err != nil {
+			if !options.keepGoing {
+				return err
+			}
+			options.logger.Printf("warning: failed to generate %s: %s", tableName, err)
+			tableErrors = append(tableErrors, TableError{TableName: tableName, Err: err})
+		}
+	}
+	if options.embedded != nil && options.embedded.goTypes != nil {
+		err = writeEmbeddedStruct(dbName, options.embedded, options)
 		if err != nil {
 			return err
 		}
 	}
-
-	return err
+	if options.registry != nil {
+		if err = options.registry.write(dbName, options); err != nil {
+			return err
+		}
+	}
+	if options.proto != nil {
+		if err = options.proto.write(dbName, options); err != nil {
+			return err
+		}
+	}
+	if options.manifest != nil {
+		if err = options.manifest.write(options); err != nil {
+			return err
+		}
+	}
+	if options.goGenerate != nil {
+		if err = writeGoGenerateFile(dbName, options); err != nil {
+			return err
+		}
+	}
+	if options.gitAttributes && options.stdout == nil && options.writerFor == nil {
+		if err = writeGitAttributes(options); err != nil {
+			return err
+		}
+	}
+	if options.stdout != nil {
+		if err = options.stdout.writeTo(os.Stdout, dbName, options); err != nil {
+			return err
+		}
+	}
+	if len(tableErrors) != 0 {
+		return tableErrors
+	}
+	return nil
 }
@@ -0,0 +1,1730 @@
+package generator
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// discardLogger drops the messages of the generation in tests.
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, v ...interface{}) {}
+
+// newTestDatabase creates the SQLite database app.db in a temporary directory with the statements
+// and returns its path.
+func newTestDatabase(t *testing.T, statements ...string) string {
+	t.Helper()
+	return newNamedTestDatabase(t, t.TempDir(), "app", statements...)
+}
+
+// newNamedTestDatabase creates the SQLite database name.db in dir with the statements and returns its path.
+func newNamedTestDatabase(t *testing.T, dir, name string, statements ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, name+".db")
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, statement := range statements {
+		if _, err = db.Exec(statement); err != nil {
+			t.Fatalf("%s: %s", statement, err)
+		}
+	}
+	return path
+}
+
+// newTestSchemaFetcher returns the SQLite schema fetcher of the database.
+func newTestSchemaFetcher(t *testing.T, dataSourceName string, options options) SchemaFetcher {
+	t.Helper()
+	db, err := sql.Open("sqlite3", dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return newSQLite3SchemaFetcher(db, options)
+}
+
+// chdirTemp changes the working directory to a temporary directory for the test.
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+// generateTestFiles generates the SQLite database into a temporary directory, unless config gives
+// the output path, and returns the contents of the generated files by their path relative to it.
+func generateTestFiles(t *testing.T, dataSourceName string, config Config) map[string]string {
+	t.Helper()
+	if config.Output == "" {
+		config.Output = t.TempDir()
+	}
+	config.DataSourceName = dataSourceName
+	if config.Logger == nil {
+		config.Logger = discardLogger{}
+	}
+	if err := GenerateWithConfig("sqlite3", config); err != nil {
+		t.Fatal(err)
+	}
+	return readTestFiles(t, config.Output)
+}
+
+// readTestFiles returns the contents of the files of dir by their path relative to it.
+func readTestFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := make(map[string]string)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		files[filepath.ToSlash(relPath)] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestGenerateSeveralDataSourceNames(t *testing.T) {
+	dir := t.TempDir()
+	shop := newNamedTestDatabase(t, dir, "shop", "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	blog := newNamedTestDatabase(t, dir, "blog", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	err := GenerateWithConfig("", Config{Output: output, DataSourceNames: []string{shop, blog}, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := readTestFiles(t, output)
+	if len(files) != 2 || !strings.HasPrefix(files["shop/orders.go"], "// Code generated") || !strings.Contains(files["blog/posts.go"], "package blog") {
+		t.Errorf("generated files %v, want shop/orders.go and blog/posts.go", files)
+	}
+}
+
+func TestGenerateSeveralDataSourceNamesErrors(t *testing.T) {
+	dir := t.TempDir()
+	shop := newNamedTestDatabase(t, dir, "shop", "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	empty := newNamedTestDatabase(t, dir, "empty")
+	err := GenerateWithConfig("sqlite3", Config{Output: t.TempDir(), DataSourceNames: []string{shop, empty}, Logger: discardLogger{}})
+	var dataSourceErrors DataSourceErrors
+	if !errors.As(err, &dataSourceErrors) || len(dataSourceErrors) != 1 || !errors.Is(dataSourceErrors[0], ErrNoTables) {
+		t.Errorf("GenerateWithConfig() = %v, want the ErrNoTables of the empty database", err)
+	}
+
+	err = GenerateWithConfig("", Config{Output: t.TempDir(), DataSourceNames: []string{shop, "postgres://localhost/app"}, Logger: discardLogger{}})
+	if err == nil || !strings.Contains(err.Error(), "different drivers") {
+		t.Errorf("GenerateWithConfig() = %v, want the drivers to differ", err)
+	}
+}
+
+func TestGenerateSeveralDataSourceNamesDryRun(t *testing.T) {
+	dir := t.TempDir()
+	shop := newNamedTestDatabase(t, dir, "shop", "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	blog := newNamedTestDatabase(t, dir, "blog", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	stdout := captureStdout(t, func() {
+		if err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceNames: []string{shop, blog}, DryRun: true, Logger: discardLogger{}}); err != nil {
+			t.Error(err)
+		}
+	})
+	entries, err := os.ReadDir(output)
+	if err != nil || len(entries) != 0 {
+		t.Errorf("the dry run created %v, %v", entries, err)
+	}
+	if !strings.Contains(stdout, filepath.Join(output, "shop", "orders.go")+":") {
+		t.Errorf("the dry run printed %q, want shop/orders.go", stdout)
+	}
+}
+
+func TestGenerateSeveralDataSourceNamesWriterFor(t *testing.T) {
+	dir := t.TempDir()
+	shop := newNamedTestDatabase(t, dir, "shop", "CREATE TABLE orders (id INTEGER PRIMARY KEY)")
+	blog := newNamedTestDatabase(t, dir, "blog", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	writers := make(testWriters)
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceNames: []string{shop, blog}, WriterFor: writers.writerFor, Logger: discardLogger{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := os.ReadDir(output); err != nil || len(entries) != 0 {
+		t.Errorf("WriterFor created %v, %v", entries, err)
+	}
+	if len(writers) != 2 || writers["orders"] == nil || writers["posts"] == nil {
+		t.Errorf("WriterFor got %v, want orders and posts", writers)
+	}
+}
+
+// testWriters holds the files written by Config.WriterFor by their names.
+type testWriters map[string]*bytes.Buffer
+
+type testWriter struct {
+	*bytes.Buffer
+}
+
+func (testWriter) Close() error {
+	return nil
+}
+
+func (w testWriters) writerFor(name string) (io.WriteCloser, error) {
+	w[name] = new(bytes.Buffer)
+	return testWriter{w[name]}, nil
+}
+
+// captureStdout returns what f prints to stdout.
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	output := make(chan string)
+	go func() {
+		content, _ := io.ReadAll(r)
+		output <- string(content)
+	}()
+	f()
+	w.Close()
+	return <-output
+}
+
+// testGoType returns the Go type of the column with the options of config.
+func testGoType(t *testing.T, driverName string, config Config, fieldDescriptor FieldDescriptor) string {
+	t.Helper()
+	options, err := newOptions(driverName, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	goType, _, err := getType(fieldDescriptor, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return goType
+}
+
+func TestGetTypeUnsigned(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		allowNull bool
+		goType    string
+	}{
+		{"tinyint", false, "uint8"},
+		{"tinyint", true, "*uint8"},
+		{"smallint", false, "uint16"},
+		{"smallint", true, "*uint16"},
+		{"mediumint", false, "uint32"},
+		{"mediumint", true, "*uint32"},
+		{"int", false, "uint32"},
+		{"int", true, "*uint32"},
+		{"bigint", false, "uint64"},
+		{"bigint", true, "*uint64"},
+		// floating point types have no unsigned Go type
+		{"float", false, "float64"},
+		{"double", true, "*float64"},
+		{"decimal", false, "float64"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "c", Type: test.fieldType, Unsigned: true, AllowNull: test.allowNull}
+		if goType := testGoType(t, "mysql", Config{}, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(%s unsigned, null %v) = %s, want %s", test.fieldType, test.allowNull, goType, test.goType)
+		}
+	}
+}
+
+func TestRegisterTypeMapper(t *testing.T) {
+	typeMappersMu.Lock()
+	registered := typeMappers["postgres"]
+	typeMappersMu.Unlock()
+	t.Cleanup(func() {
+		typeMappersMu.Lock()
+		typeMappers["postgres"] = registered
+		typeMappersMu.Unlock()
+	})
+	RegisterTypeMapper("postgres", func(fieldDescriptor FieldDescriptor) (string, []string, bool) {
+		if fieldDescriptor.Type != "citext" {
+			return "", nil, false
+		}
+		return "types.CIText", []string{"example.com/types"}, true
+	})
+
+	options, err := newOptions("postgres", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		goType          string
+		imports         []string
+	}{
+		{FieldDescriptor{Name: "email", Type: "citext"}, "types.CIText", []string{"example.com/types"}},
+		{FieldDescriptor{Name: "email", Type: "citext", AllowNull: true}, "*types.CIText", []string{"example.com/types"}},
+		// the types the mapper doesn't handle fall through to the built-in mappings
+		{FieldDescriptor{Name: "name", Type: "text"}, "string", nil},
+	}
+	for _, test := range tests {
+		goType, imports, err := getType(test.fieldDescriptor, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goType != test.goType || !reflect.DeepEqual(imports, test.imports) {
+			t.Errorf("getType(%+v) = %s, %v, want %s, %v", test.fieldDescriptor, goType, imports, test.goType, test.imports)
+		}
+	}
+	// the mappers are registered for a driver
+	mysqlOptions, err := newOptions("mysql", Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if goType, _, _ := getType(FieldDescriptor{Name: "email", Type: "citext"}, mysqlOptions); goType != "string" {
+		t.Errorf("getType(citext) = %s for mysql, want string", goType)
+	}
+}
+
+func TestGetTagDefault(t *testing.T) {
+	options, err := newOptions("postgres", Config{Tags: []string{"gorm"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := func(s string) *string { return &s }
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		tag             string
+	}{
+		{FieldDescriptor{Name: "count", Type: "int", DefaultValue: value("0")}, `gorm:"column:count;default:0"`},
+		{FieldDescriptor{Name: "created_at", Type: "timestamp", DefaultValue: value("CURRENT_TIMESTAMP")}, `gorm:"column:created_at;default:CURRENT_TIMESTAMP"`},
+		{FieldDescriptor{Name: "status", Type: "text", DefaultValue: value("'new'")}, `gorm:"column:status;default:'new'"`},
+		{FieldDescriptor{Name: "id", Type: "int", PrimaryKeyOrdinal: 1, IsAutoIncrement: true, DefaultValue: value("nextval('users_id_seq'::regclass)")}, `gorm:"column:id;primaryKey;autoIncrement"`},
+		{FieldDescriptor{Name: "number", Type: "int", DefaultValue: value("nextval('numbers_seq')")}, `gorm:"column:number"`},
+	}
+	for _, test := range tests {
+		if tag := getTag(test.fieldDescriptor, "", options); tag != "`"+test.tag+"`" {
+			t.Errorf("getTag(%s) = %s, want `%s`", test.fieldDescriptor.Name, tag, test.tag)
+		}
+	}
+}
+
+func TestDefaultComment(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE counters (id INTEGER PRIMARY KEY, count INTEGER NOT NULL DEFAULT 0, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)")
+	file := generateTestFiles(t, dataSourceName, Config{})["counters.go"]
+	for _, want := range []string{"\tCount int64 `` // default: 0\n", "\tCreatedAt *time.Time `` // default: CURRENT_TIMESTAMP\n"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("counters.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestJSONStringInts(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id bigint NOT NULL PRIMARY KEY, name TEXT)")
+	file := generateTestFiles(t, dataSourceName, Config{Tags: []string{"json"}, JSONStringInts: true, OmitEmpty: true})["users.go"]
+	for _, want := range []string{"`json:\"id,string\"`", "`json:\"name,omitempty\"`"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("users.go = %q, want %s", file, want)
+		}
+	}
+}
+
+func TestGetTypePostgresSpecialTypes(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		config    Config
+		goType    string
+	}{
+		{"inet", Config{}, "string"},
+		{"cidr", Config{}, "string"},
+		{"macaddr", Config{}, "string"},
+		{"inet", Config{NetTypes: true}, "net.IP"},
+		{"cidr", Config{NetTypes: true}, "net.IPNet"},
+		{"macaddr", Config{NetTypes: true}, "net.HardwareAddr"},
+		{"interval", Config{}, "string"},
+		{"money", Config{}, "string"},
+		{"money", Config{Decimal: true}, "decimal.Decimal"},
+	}
+	for _, test := range tests {
+		if goType := testGoType(t, "postgres", test.config, FieldDescriptor{Name: "value", Type: test.fieldType}); goType != test.goType {
+			t.Errorf("getType(%s, %+v) = %s, want %s", test.fieldType, test.config, goType, test.goType)
+		}
+	}
+}
+
+func TestGetTypeJSON(t *testing.T) {
+	tests := []struct {
+		driverName string
+		fieldType  string
+		jsonType   string
+		allowNull  bool
+		goType     string
+	}{
+		{"mysql", "json", "", false, "string"},
+		{"mysql", "json", "raw", false, "json.RawMessage"},
+		{"mysql", "json", "raw", true, "*json.RawMessage"},
+		{"postgres", "jsonb", "", true, "*string"},
+		{"postgres", "jsonb", "raw", false, "json.RawMessage"},
+		{"postgres", "json", "raw", true, "*json.RawMessage"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "data", Type: test.fieldType, AllowNull: test.allowNull}
+		if goType := testGoType(t, test.driverName, Config{JSONType: test.jsonType}, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(%s %s, -jsontype %q, null %v) = %s, want %s", test.driverName, test.fieldType, test.jsonType, test.allowNull, goType, test.goType)
+		}
+	}
+}
+
+func TestMapTypes(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE Users (id INTEGER PRIMARY KEY, settings TEXT, created BIGINT, deleted_at BIGINT)")
+	file := generateTestFiles(t, dataSourceName, Config{
+		MapTypes: []string{"users.SETTINGS=json.RawMessage", "Users.created=time.Time", "users.deleted_at=*time.Time"},
+		Imports:  []string{"encoding/json", "time"},
+	})["Users.go"]
+	for _, want := range []string{
+		"\t\"encoding/json\"\n",
+		"\t\"time\"\n",
+		"\tSettings json.RawMessage ``\n",
+		"\tCreated time.Time ``\n",
+		"\tDeletedAt *time.Time ``\n",
+	} {
+		if !strings.Contains(file, want) {
+			t.Errorf("Users.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestOnUnknown(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE shapes (id INTEGER PRIMARY KEY, area strange, name TEXT)")
+	tests := []struct {
+		onUnknown      string
+		emptyInterface string
+		want           string
+	}{
+		{"skip", "", "type Shapes struct {\n\t// skipped area: unknown field type strange\n\tId int64 ``\n\tName *string ``\n}"},
+		{"any", "", "\t// area: unknown field type strange\n\tArea any ``\n"},
+		{"any", "interface{}", "\t// area: unknown field type strange\n\tArea interface{} ``\n"},
+	}
+	for _, test := range tests {
+		file := generateTestFiles(t, dataSourceName, Config{OnUnknown: test.onUnknown, EmptyInterface: test.emptyInterface})["shapes.go"]
+		if !strings.Contains(file, test.want) {
+			t.Errorf("-onunknown %s: shapes.go = %q, want %q", test.onUnknown, file, test.want)
+		}
+	}
+
+	for _, onUnknown := range []string{"", "error"} {
+		err := GenerateWithConfig("sqlite3", Config{Output: t.TempDir(), DataSourceName: dataSourceName, OnUnknown: onUnknown, Logger: discardLogger{}})
+		if err == nil || !strings.Contains(err.Error(), "strange") {
+			t.Errorf("-onunknown %q: GenerateWithConfig() = %v, want the unknown type", onUnknown, err)
+		}
+	}
+}
+
+func TestFileCase(t *testing.T) {
+	dataSourceName := newTestDatabase(t, `CREATE TABLE "User-Data" (id INTEGER PRIMARY KEY)`, `CREATE TABLE "a:b" (id INTEGER PRIMARY KEY)`)
+	tests := []struct {
+		fileCase string
+		files    []string
+	}{
+		{"", []string{"User-Data.go", "a_b.go"}},
+		{"table", []string{"User-Data.go", "a_b.go"}},
+		{"snake", []string{"user_data.go", "a_b.go"}},
+		{"struct", []string{"UserData.go", "AB.go"}},
+	}
+	for _, test := range tests {
+		files := generateTestFiles(t, dataSourceName, Config{FileCase: test.fileCase})
+		for _, name := range test.files {
+			if _, ok := files[name]; !ok {
+				t.Errorf("-filecase %q: %s not generated", test.fileCase, name)
+			}
+		}
+	}
+}
+
+func TestTrimPrefixes(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE t_user (id INTEGER PRIMARY KEY)", "CREATE TABLE tbl_t_order (id INTEGER PRIMARY KEY)", "CREATE TABLE item (id INTEGER PRIMARY KEY)")
+	files := generateTestFiles(t, dataSourceName, Config{TrimPrefixes: []string{"tbl_", "t_"}})
+	tests := []struct {
+		fileName   string
+		structName string
+		tableName  string
+	}{
+		{"t_user.go", "User", "t_user"},
+		{"tbl_t_order.go", "TOrder", "tbl_t_order"},
+		{"item.go", "Item", "item"},
+	}
+	for _, test := range tests {
+		file := files[test.fileName]
+		if !strings.Contains(file, "type "+test.structName+" struct {") || !strings.Contains(file, "\treturn \""+test.tableName+"\"\n") {
+			t.Errorf("%s = %q, want struct %s of table %s", test.fileName, file, test.structName, test.tableName)
+		}
+	}
+}
+
+func TestStructPrefixAndSuffix(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	file := generateTestFiles(t, dataSourceName, Config{StructPrefix: "DB", StructSuffix: "Model"})["users.go"]
+	for _, want := range []string{"type DBUsersModel struct {", "func (m DBUsersModel) TableName() string {\n\treturn \"users\"\n}"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("users.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestGetTypeBoolColumns(t *testing.T) {
+	tests := []struct {
+		size        int
+		boolColumns bool
+		allowNull   bool
+		goType      string
+	}{
+		{1, true, false, "bool"},
+		{1, true, true, "*bool"},
+		{4, true, false, "int8"},
+		{0, true, false, "int8"},
+		{1, false, false, "int8"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "active", Type: "tinyint", Size: test.size, AllowNull: test.allowNull}
+		if goType := testGoType(t, "mysql", Config{BoolColumns: test.boolColumns}, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(tinyint(%d), -boolcolumns %v, null %v) = %s, want %s", test.size, test.boolColumns, test.allowNull, goType, test.goType)
+		}
+	}
+}
+
+func TestConvertToExportedIdentifier(t *testing.T) {
+	tests := []struct {
+		name       string
+		forceCases []string
+		identifier string
+	}{
+		{"user_id", []string{"ID"}, "UserID"},
+		{"html_body", []string{"HTML"}, "HTMLBody"},
+		{"api_url", []string{"API", "URL"}, "APIURL"},
+		{"id", []string{"ID"}, "ID"},
+		{"ios_version", []string{"iOS"}, "IOSVersion"},
+		{"user_id", nil, "UserId"},
+		{"userName", nil, "UserName"},
+		{"1st_place", nil, "E1StPlace"},
+		{"_", nil, "E"},
+	}
+	for _, test := range tests {
+		if identifier := convertToExportedIdentifier(test.name, test.forceCases); identifier != test.identifier {
+			t.Errorf("convertToExportedIdentifier(%q, %q) = %s, want %s", test.name, test.forceCases, identifier, test.identifier)
+		}
+	}
+}
+
+func TestHeader(t *testing.T) {
+	header := filepath.Join(t.TempDir(), "header.txt")
+	if err := os.WriteFile(header, []byte("// Copyright 2026 Acme\n// Licensed under MIT\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	tests := []struct {
+		headerReplace bool
+		want          string
+	}{
+		{false, "// Copyright 2026 Acme\n// Licensed under MIT\n\n// Code generated by sqlmodel; DO NOT EDIT.\npackage app \n\n"},
+		{true, "// Copyright 2026 Acme\n// Licensed under MIT\n\npackage app \n\n"},
+	}
+	for _, test := range tests {
+		file := generateTestFiles(t, dataSourceName, Config{Header: header, HeaderReplace: test.headerReplace})["users.go"]
+		if !strings.HasPrefix(file, test.want) {
+			t.Errorf("-header-replace %v: users.go = %q, want it to start with %q", test.headerReplace, file, test.want)
+		}
+	}
+}
+
+func TestBuildTags(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	file := generateTestFiles(t, dataSourceName, Config{BuildTags: "generated && !test"})["users.go"]
+	want := "//go:build generated && !test\n// +build generated,!test\n\n// Code generated by sqlmodel; DO NOT EDIT.\npackage app \n\n"
+	if !strings.HasPrefix(file, want) {
+		t.Errorf("users.go = %q, want it to start with %q", file, want)
+	}
+
+	if _, err := newOptions("sqlite3", Config{BuildTags: "generated &&"}); err == nil {
+		t.Error("newOptions() accepted an invalid build constraint")
+	}
+}
+
+func TestListTables(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)", "CREATE TABLE posts (id INTEGER PRIMARY KEY)", "CREATE VIEW user_names AS SELECT id FROM users")
+	chdirTemp(t)
+	tableNames, err := ListTables("sqlite3", dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"users", "posts"}; !reflect.DeepEqual(tableNames, want) {
+		t.Errorf("ListTables() = %v, want %v", tableNames, want)
+	}
+	if entries, err := os.ReadDir("."); err != nil || len(entries) != 0 {
+		t.Errorf("ListTables() wrote %v, %v", entries, err)
+	}
+
+	if _, err = ListTables("oracle", dataSourceName); err == nil {
+		t.Error("ListTables() succeeded with an unsupported driver")
+	}
+}
+
+func TestGetTagValidate(t *testing.T) {
+	options, err := newOptions("mysql", Config{Tags: []string{"validate", "json"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		goType          string
+		tag             string
+	}{
+		{FieldDescriptor{Name: "name", Type: "varchar", Size: 50}, "string", `json:"name" validate:"required,max=50"`},
+		{FieldDescriptor{Name: "nickname", Type: "varchar", Size: 20, AllowNull: true}, "*string", `json:"nickname" validate:"max=20"`},
+		{FieldDescriptor{Name: "bio", Type: "text", AllowNull: true}, "*string", `json:"bio"`},
+		{FieldDescriptor{Name: "age", Type: "int", Size: 11}, "int32", `json:"age" validate:"required"`},
+	}
+	for _, test := range tests {
+		if tag := getTag(test.fieldDescriptor, test.goType, options); tag != "`"+test.tag+"`" {
+			t.Errorf("getTag(%s) = %s, want `%s`", test.fieldDescriptor.Name, tag, test.tag)
+		}
+	}
+}
+
+func TestViews(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)", "CREATE VIEW user_names AS SELECT id, name FROM users")
+	files := generateTestFiles(t, dataSourceName, Config{Tags: []string{"gorm"}})
+	if _, ok := files["user_names.go"]; ok {
+		t.Error("user_names.go generated without -views")
+	}
+
+	files = generateTestFiles(t, dataSourceName, Config{Tags: []string{"gorm"}, Views: true})
+	want := "// UserNames is generated from view user_names.\ntype UserNames struct {\n\tId *int64 `gorm:\"column:id\"`\n"
+	if file := files["user_names.go"]; !strings.Contains(file, want) {
+		t.Errorf("user_names.go = %q, want %q", file, want)
+	}
+	if file := files["users.go"]; strings.Contains(file, "view") || !strings.Contains(file, "primaryKey") {
+		t.Errorf("users.go = %q, want the table with its primary key", file)
+	}
+}
+
+// testSchemaFetcher is a SchemaFetcher of fixed tables, for the generation of schemas no test
+// database can hold.
+type testSchemaFetcher struct {
+	dbName string
+	tables map[string][]FieldDescriptor
+	views  map[string][]FieldDescriptor
+}
+
+func (f testSchemaFetcher) GetDatabaseName() (string, error) {
+	return f.dbName, nil
+}
+
+func (f testSchemaFetcher) GetTableNames() (tableNames []string, err error) {
+	for tableName := range f.tables {
+		tableNames = append(tableNames, tableName)
+	}
+	return
+}
+
+func (f testSchemaFetcher) GetViewNames() (viewNames []string, err error) {
+	for viewName := range f.views {
+		viewNames = append(viewNames, viewName)
+	}
+	return
+}
+
+func (f testSchemaFetcher) GetFieldDescriptors(tableName string) ([]FieldDescriptor, error) {
+	if fieldDescriptors, ok := f.tables[tableName]; ok {
+		return fieldDescriptors, nil
+	}
+	if fieldDescriptors, ok := f.views[tableName]; ok {
+		return fieldDescriptors, nil
+	}
+	return nil, errors.New("no table " + tableName)
+}
+
+func (f testSchemaFetcher) QuoteIdentifier(identifier string) string {
+	return "\"" + strings.ReplaceAll(identifier, "\"", "\"\"") + "\""
+}
+
+func TestSchemas(t *testing.T) {
+	id := []FieldDescriptor{{Name: "id", Type: "integer", PrimaryKeyOrdinal: 1}}
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{
+		"app.users":        id,
+		"analytics.users":  id,
+		"analytics.visits": id,
+	}}
+	output := t.TempDir()
+	if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, Schemas: []string{"app", "analytics"}, QuoteIdentifiers: true, Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	files := readTestFiles(t, output)
+	tests := []struct {
+		fileName   string
+		structName string
+		tableName  string
+		quotedName string
+	}{
+		{"app.users.go", "AppUsers", "app.users", `"app"."users"`},
+		{"analytics.users.go", "AnalyticsUsers", "analytics.users", `"analytics"."users"`},
+		{"analytics.visits.go", "Visits", "analytics.visits", `"analytics"."visits"`},
+	}
+	for _, test := range tests {
+		file := files[test.fileName]
+		for _, want := range []string{"type " + test.structName + " struct {", "\treturn \"" + test.tableName + "\"\n", "\treturn " + strconv.Quote(test.quotedName) + "\n"} {
+			if !strings.Contains(file, want) {
+				t.Errorf("%s = %q, want %q", test.fileName, file, want)
+			}
+		}
+	}
+}
+
+func TestStdout(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, created DATETIME)", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	stdout := captureStdout(t, func() {
+		if err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, Stdout: true, Overwrite: "prompt", Logger: discardLogger{}}); err != nil {
+			t.Error(err)
+		}
+	})
+	if entries, err := os.ReadDir(output); err != nil || len(entries) != 0 {
+		t.Errorf("-stdout wrote %v, %v", entries, err)
+	}
+	if !strings.HasPrefix(stdout, "// Code generated") || strings.Count(stdout, "package app") != 1 || strings.Count(stdout, "import \"time\"") != 1 {
+		t.Errorf("-stdout printed %q, want a single file", stdout)
+	}
+	if i, j := strings.Index(stdout, "type Posts struct"), strings.Index(stdout, "type Users struct"); i < 0 || j < i {
+		t.Errorf("-stdout printed %q, want Posts and Users", stdout)
+	}
+	typeCheck(t, stdout)
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	// the tables of testSchemaFetcher come in the random order of its map
+	tables := make(map[string][]FieldDescriptor)
+	for _, tableName := range []string{"users", "posts", "comments", "tags", "likes", "follows"} {
+		tables[tableName] = []FieldDescriptor{{Name: "id", Type: "integer", PrimaryKeyOrdinal: 1}, {Name: "name", Type: "text", AllowNull: true}}
+	}
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: tables}
+	var outputs []map[string]string
+	var stdouts []string
+	for i := 0; i < 2; i++ {
+		output := t.TempDir()
+		if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, Registry: true, Logger: discardLogger{}}); err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, readTestFiles(t, output))
+		stdouts = append(stdouts, captureStdout(t, func() {
+			if err := GenerateWithFetcher(schemaFetcher, Config{Stdout: true, Logger: discardLogger{}}); err != nil {
+				t.Error(err)
+			}
+		}))
+	}
+	if !reflect.DeepEqual(outputs[0], outputs[1]) {
+		t.Errorf("the runs generated %v and %v", outputs[0], outputs[1])
+	}
+	if stdouts[0] != stdouts[1] {
+		t.Errorf("the runs printed %q and %q", stdouts[0], stdouts[1])
+	}
+}
+
+func TestKeepGoing(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE shapes (id INTEGER PRIMARY KEY, area strange)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY)",
+	)
+	output := t.TempDir()
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, KeepGoing: true, Logger: discardLogger{}})
+	var tableErrors TableErrors
+	if !errors.As(err, &tableErrors) || len(tableErrors) != 1 || tableErrors[0].TableName != "shapes" || !errors.Is(tableErrors[0], errUnknownFieldType) {
+		t.Fatalf("GenerateWithConfig() = %v, want the unknown type of shapes", err)
+	}
+	files := readTestFiles(t, output)
+	if _, ok := files["shapes.go"]; len(files) != 2 || ok || files["users.go"] == "" || files["posts.go"] == "" {
+		t.Errorf("-keepgoing generated %d files, want users.go and posts.go", len(files))
+	}
+
+	// without -keepgoing, the tables after shapes are not generated
+	output = t.TempDir()
+	err = GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, Logger: discardLogger{}})
+	if !errors.Is(err, errUnknownFieldType) || errors.As(err, &tableErrors) {
+		t.Errorf("GenerateWithConfig() = %v, want the unknown type", err)
+	}
+	if files := readTestFiles(t, output); len(files) != 1 || files["posts.go"] == "" {
+		t.Errorf("the generation stopped after %d files, want posts.go only", len(files))
+	}
+}
+
+func TestGetTypeYear(t *testing.T) {
+	tests := []struct {
+		yearType  string
+		allowNull bool
+		goType    string
+	}{
+		{"", false, "int16"},
+		{"", true, "*int16"},
+		{"int16", false, "int16"},
+		{"string", false, "string"},
+		{"string", true, "*string"},
+	}
+	for _, test := range tests {
+		fieldDescriptor := FieldDescriptor{Name: "released", Type: "year", AllowNull: test.allowNull}
+		if goType := testGoType(t, "mysql", Config{YearType: test.yearType}, fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(year, -yeartype %q, null %v) = %s, want %s", test.yearType, test.allowNull, goType, test.goType)
+		}
+	}
+}
+
+func TestGetTypeDateType(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		dateType  string
+		goType    string
+		imports   []string
+	}{
+		{"date", "", "time.Time", []string{"time"}},
+		{"date", "time", "time.Time", []string{"time"}},
+		{"date", "civil", "civil.Date", []string{"cloud.google.com/go/civil"}},
+		{"date", "string", "string", nil},
+		{"datetime", "civil", "time.Time", []string{"time"}},
+		{"timestamp", "string", "time.Time", []string{"time"}},
+	}
+	for _, test := range tests {
+		options, err := newOptions("mysql", Config{DateType: test.dateType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		goType, imports, err := getType(FieldDescriptor{Name: "birthday", Type: test.fieldType}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goType != test.goType || !reflect.DeepEqual(imports, test.imports) {
+			t.Errorf("getType(%s, -datetype %q) = %s, %q, want %s, %q", test.fieldType, test.dateType, goType, imports, test.goType, test.imports)
+		}
+	}
+}
+
+// failingSchemaFetcher fails to get the database name the given number of times.
+type failingSchemaFetcher struct {
+	testSchemaFetcher
+	failures *int
+}
+
+func (f failingSchemaFetcher) GetDatabaseName() (string, error) {
+	if *f.failures > 0 {
+		*f.failures--
+		return "", errors.New("connection refused")
+	}
+	return f.testSchemaFetcher.GetDatabaseName()
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		failures int
+		retry    int
+		ok       bool
+	}{
+		{0, 0, true},
+		{1, 0, false},
+		{2, 3, true},
+		{3, 3, true},
+		{4, 3, false},
+	}
+	for _, test := range tests {
+		failures := test.failures
+		schemaFetcher := failingSchemaFetcher{testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"users": {{Name: "id", Type: "integer"}}}}, &failures}
+		err := GenerateWithFetcher(schemaFetcher, Config{Output: t.TempDir(), Retry: test.retry, RetryInterval: "1ms", Logger: discardLogger{}})
+		if ok := err == nil; ok != test.ok {
+			t.Errorf("%d failures with -retry %d: GenerateWithFetcher() = %v", test.failures, test.retry, err)
+		}
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)", "CREATE TABLE posts (id INTEGER PRIMARY KEY)", "CREATE TABLE tags (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	files := generateTestFiles(t, dataSourceName, Config{Output: output, Tables: []string{"users", "posts"}})
+	if err := os.WriteFile(filepath.Join(output, "posts.go"), []byte("package app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	stdout := captureStdout(t, func() {
+		if err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, DryRun: true, Logger: discardLogger{}}); err != nil {
+			t.Error(err)
+		}
+	})
+	for _, want := range []string{
+		fmt.Sprintf("%s: %d bytes, unchanged\n", filepath.Join(output, "users.go"), len(files["users.go"])),
+		fmt.Sprintf("%s: %d bytes, changed\n", filepath.Join(output, "posts.go"), len(files["posts.go"])),
+		filepath.Join(output, "tags.go") + ": ",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("the dry run printed %q, want %q", stdout, want)
+		}
+	}
+	if !strings.Contains(stdout, "bytes, new\n") {
+		t.Errorf("the dry run printed %q, want tags.go new", stdout)
+	}
+	written := readTestFiles(t, output)
+	if _, ok := written["tags.go"]; ok || written["posts.go"] != "package app\n" {
+		t.Errorf("the dry run wrote %v", written)
+	}
+}
+
+func TestConvertToExportedIdentifierLeadingDigits(t *testing.T) {
+	tests := []struct {
+		name       string
+		identifier string
+	}{
+		{"3d_model", "E3DModel"},
+		{"3D_model", "E3DModel"},
+		{"2fa", "E2Fa"},
+		{"9_lives", "E9Lives"},
+		{"42", "E42"},
+		{"_hidden", "Hidden"},
+		{"__", "E"},
+		{"", "E"},
+	}
+	for _, test := range tests {
+		if identifier := convertToExportedIdentifier(test.name, nil); identifier != test.identifier {
+			t.Errorf("convertToExportedIdentifier(%q) = %s, want %s", test.name, identifier, test.identifier)
+		}
+	}
+}
+
+func TestFieldOrder(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (name TEXT, id INTEGER PRIMARY KEY, email TEXT)")
+	tests := []struct {
+		fieldOrder string
+		fields     []string
+	}{
+		{"", []string{"Name", "Id", "Email"}},
+		{"schema", []string{"Name", "Id", "Email"}},
+		{"alpha", []string{"Email", "Id", "Name"}},
+	}
+	for _, test := range tests {
+		file := generateTestFiles(t, dataSourceName, Config{FieldOrder: test.fieldOrder})["users.go"]
+		var fields []string
+		for _, line := range strings.Split(file, "\n") {
+			if strings.HasPrefix(line, "\t") && strings.HasSuffix(line, "``") {
+				fields = append(fields, strings.Fields(line)[0])
+			}
+		}
+		if !reflect.DeepEqual(fields, test.fields) {
+			t.Errorf("-fieldorder %q gave the fields %v, want %v", test.fieldOrder, fields, test.fields)
+		}
+	}
+	if _, err := newOptions("sqlite3", Config{FieldOrder: "random"}); err == nil {
+		t.Error("newOptions() accepted -fieldorder random")
+	}
+}
+
+func TestGetTypePostgresStrings(t *testing.T) {
+	for _, fieldType := range []string{"character", "bpchar", "name", "text", "character varying", "citext"} {
+		for _, allowNull := range []bool{false, true} {
+			want := "string"
+			if allowNull {
+				want = "*string"
+			}
+			if goType := testGoType(t, "postgres", Config{}, FieldDescriptor{Name: "value", Type: fieldType, AllowNull: allowNull}); goType != want {
+				t.Errorf("getType(%s, null %v) = %s, want %s", fieldType, allowNull, goType, want)
+			}
+		}
+	}
+}
+
+func TestChanged(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	files := generateTestFiles(t, dataSourceName, Config{Output: output})
+	users, posts := filepath.Join(output, "users.go"), filepath.Join(output, "posts.go")
+	if err := os.WriteFile(posts, []byte("package app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, path := range []string{users, posts} {
+		if err := os.Chtimes(path, past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stdout := captureStdout(t, func() {
+		if err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, Changed: true, Logger: discardLogger{}}); err != nil {
+			t.Error(err)
+		}
+	})
+	if want := posts + ": changed\n"; stdout != want {
+		t.Errorf("-changed printed %q, want %q", stdout, want)
+	}
+	if info, err := os.Stat(users); err != nil || !info.ModTime().Equal(past) {
+		t.Errorf("-changed rewrote the unchanged users.go")
+	}
+	if written := readTestFiles(t, output); written["posts.go"] != files["posts.go"] {
+		t.Errorf("-changed wrote posts.go %q, want %q", written["posts.go"], files["posts.go"])
+	}
+}
+
+func TestJSONMethodsRoundTrip(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, nick_name TEXT, email TEXT)")
+	files := generateTestFiles(t, dataSourceName, Config{Package: "main", JSONMethods: true, JSONStringInts: true})
+	files["main.go"] = `package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+func main() {
+	nickName := "ann"
+	data, err := json.Marshal(Users{Id: 1, NickName: &nickName})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(string(data))
+	var users Users
+	if err = json.Unmarshal(data, &users); err != nil {
+		panic(err)
+	}
+	fmt.Println(users.Id, *users.NickName, users.Email == nil)
+}
+`
+	if output, want := runGoProgram(t, files), "{\"id\":\"1\",\"nick_name\":\"ann\",\"email\":null}\n1 ann true\n"; output != want {
+		t.Errorf("the round trip printed %q, want %q", output, want)
+	}
+}
+
+func TestConstructors(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, email TEXT, status TEXT NOT NULL DEFAULT 'new', created_at DATETIME NOT NULL)",
+		"CREATE TABLE tags (code TEXT NOT NULL PRIMARY KEY, label TEXT NOT NULL)",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{Constructors: true})
+	tests := []struct {
+		fileName    string
+		constructor string
+	}{
+		// the auto-increment key, the nullable email and the defaulted status are left to the caller
+		{"users.go", "func NewUsers(name string, createdAt time.Time) Users {\n\tvar m Users\n\tm.Name = name\n\tm.CreatedAt = createdAt\n\treturn m\n}\n"},
+		// keys which are not auto-increment are required
+		{"tags.go", "func NewTags(code string, label string) Tags {\n"},
+	}
+	for _, test := range tests {
+		if !strings.Contains(files[test.fileName], test.constructor) {
+			t.Errorf("%s = %q, want %q", test.fileName, files[test.fileName], test.constructor)
+		}
+	}
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, first TEXT, last TEXT, full_name TEXT GENERATED ALWAYS AS (first || ' ' || last) STORED, initials TEXT GENERATED ALWAYS AS (substr(first, 1, 1)) VIRTUAL)")
+	file := generateTestFiles(t, dataSourceName, Config{Tags: []string{"gorm"}, SQLHelpers: true})["users.go"]
+	for _, want := range []string{
+		"\tFullName *string `gorm:\"column:full_name;->\"`\n",
+		"\tInitials *string `gorm:\"column:initials;->\"`\n",
+		"\tFirst *string `gorm:\"column:first\"`\n",
+		"var UsersColumns = []string{\"id\", \"first\", \"last\"}\n",
+		"\treturn []any{m.Id, m.First, m.Last}\n",
+	} {
+		if !strings.Contains(file, want) {
+			t.Errorf("users.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestQuoteIdentifiers(t *testing.T) {
+	dataSourceName := newTestDatabase(t, `CREATE TABLE "order items" (id INTEGER PRIMARY KEY, "group" TEXT, "a""b" INTEGER)`, `CREATE TABLE "select" (id INTEGER PRIMARY KEY)`)
+	files := generateTestFiles(t, dataSourceName, Config{QuoteIdentifiers: true, SQLHelpers: true})
+	tests := []struct {
+		fileName string
+		want     []string
+	}{
+		{"order items.go", []string{
+			"\tGroup *string ``\n",
+			"\treturn \"order items\"\n",
+			"\treturn \"\\\"order items\\\"\"\n",
+			"var OrderItemsQuotedColumns = []string{\"\\\"id\\\"\", \"\\\"group\\\"\", \"\\\"a\\\"\\\"b\\\"\"}\n",
+		}},
+		{"select.go", []string{"\treturn \"\\\"select\\\"\"\n"}},
+	}
+	for _, test := range tests {
+		for _, want := range test.want {
+			if !strings.Contains(files[test.fileName], want) {
+				t.Errorf("%s = %q, want %q", test.fileName, files[test.fileName], want)
+			}
+		}
+		typeCheck(t, files[test.fileName])
+	}
+}
+
+func TestAllPointers(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL, avatar BLOB, created_at DATETIME NOT NULL)")
+	file := generateTestFiles(t, dataSourceName, Config{AllPointers: true, MapTypes: []string{"users.avatar=[]byte"}})["users.go"]
+	for _, want := range []string{"\tId *int64 ``\n", "\tName *string ``\n", "\tAvatar []byte ``\n", "\tCreatedAt *time.Time ``\n"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("users.go = %q, want %q", file, want)
+		}
+	}
+
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		goType          string
+	}{
+		{FieldDescriptor{Name: "tags", Type: "text[]"}, "pq.StringArray"},
+		{FieldDescriptor{Name: "data", Type: "jsonb"}, "*string"},
+		{FieldDescriptor{Name: "price", Type: "numeric"}, "*string"},
+	}
+	for _, test := range tests {
+		if goType := testGoType(t, "postgres", Config{AllPointers: true}, test.fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(%s) = %s, want %s", test.fieldDescriptor.Type, goType, test.goType)
+		}
+	}
+}
+
+func TestUnsupportedDriver(t *testing.T) {
+	err := GenerateWithConfig("oracle", Config{Output: t.TempDir(), DataSourceName: "oracle://localhost/app", Logger: discardLogger{}})
+	if !errors.Is(err, ErrUnsupportedDriver) {
+		t.Errorf("GenerateWithConfig(oracle) = %v, want ErrUnsupportedDriver", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	unsupported := GenerateWithConfig("oracle", Config{Output: t.TempDir(), DataSourceName: "oracle://localhost/app", Logger: discardLogger{}})
+	tests := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{unsupported, 2},
+		{DataSourceErrors{{DataSourceName: "oracle://localhost/app", Err: unsupported}}, 2},
+		{fmt.Errorf("%w in database app", ErrNoTables), 1},
+		{errors.New("failed to connect"), 1},
+	}
+	for _, test := range tests {
+		if code := ExitCode(test.err); code != test.code {
+			t.Errorf("ExitCode(%v) = %d, want %d", test.err, code, test.code)
+		}
+	}
+}
+
+func TestSupportedDrivers(t *testing.T) {
+	driverNames := SupportedDrivers()
+	for _, driverName := range []string{"mysql", "sqlite3", "postgres", "sqlserver", "clickhouse"} {
+		if !containsString(driverNames, driverName) {
+			t.Errorf("SupportedDrivers() = %v, want %s", driverNames, driverName)
+		}
+	}
+	for _, driverName := range driverNames {
+		if _, err := getSchemaFetcherFactory(driverName); err != nil {
+			t.Errorf("getSchemaFetcherFactory(%s) = %v", driverName, err)
+		}
+	}
+}
+
+func TestGetTypePostgresTemporalTypes(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		timeOnly  bool
+		goType    string
+	}{
+		{"timestamp without time zone", false, "time.Time"},
+		{"timestamp with time zone", false, "time.Time"},
+		{"timestamp without time zone", true, "time.Time"},
+		{"date", true, "time.Time"},
+		{"time without time zone", false, "time.Time"},
+		{"time with time zone", false, "time.Time"},
+		{"time without time zone", true, "string"},
+		{"time with time zone", true, "string"},
+	}
+	for _, test := range tests {
+		if goType := testGoType(t, "postgres", Config{TimeOnly: test.timeOnly}, FieldDescriptor{Name: "at", Type: test.fieldType}); goType != test.goType {
+			t.Errorf("getType(%s, -timeonly %v) = %s, want %s", test.fieldType, test.timeOnly, goType, test.goType)
+		}
+	}
+}
+
+func TestPrecisionComment(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"products": {
+		{Name: "price", Type: "numeric", Precision: 12, Scale: 2},
+		{Name: "weight", Type: "decimal", Precision: 8, Scale: 3, AllowNull: true},
+		{Name: "stock", Type: "integer"},
+	}}}
+	tests := []struct {
+		config Config
+		want   []string
+	}{
+		{Config{}, []string{"\tPrice string `` // numeric(12,2)\n", "\tWeight *float64 `` // decimal(8,3)\n", "\tStock int64 ``\n"}},
+		{Config{Decimal: true}, []string{"\tPrice decimal.Decimal `` // numeric(12,2)\n"}},
+	}
+	for _, test := range tests {
+		test.config.Output = t.TempDir()
+		test.config.Logger = discardLogger{}
+		if err := GenerateWithFetcher(schemaFetcher, test.config); err != nil {
+			t.Fatal(err)
+		}
+		file := readTestFiles(t, test.config.Output)["products.go"]
+		for _, want := range test.want {
+			if !strings.Contains(file, want) {
+				t.Errorf("products.go = %q, want %q", file, want)
+			}
+		}
+	}
+}
+
+func TestTableNameMode(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	tests := []struct {
+		config  Config
+		want    string
+		notWant string
+	}{
+		{Config{}, "func (m Users) TableName() string {\n\treturn \"users\"\n}", ""},
+		{Config{TableNameMode: "pointer"}, "func (m *Users) TableName() string {\n\treturn \"users\"\n}", "func (m Users)"},
+		{Config{TableNameMode: "none"}, "type Users struct {", "TableName()"},
+		{Config{TableNameFunc: "Table"}, "func (m Users) Table() string {\n\treturn \"users\"\n}", "TableName()"},
+	}
+	for _, test := range tests {
+		file := generateTestFiles(t, dataSourceName, test.config)["users.go"]
+		if !strings.Contains(file, test.want) || test.notWant != "" && strings.Contains(file, test.notWant) {
+			t.Errorf("%+v: users.go = %q, want %q without %q", test.config, file, test.want, test.notWant)
+		}
+	}
+}
+
+func TestOverwrite(t *testing.T) {
+	// answering y through a pipe, which is not a terminal, doesn't overwrite with -overwrite prompt
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = w.WriteString("y\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+
+	tests := []struct {
+		overwrite string
+		want      string
+		skipped   bool
+	}{
+		{"always", "new", false},
+		{"never", "old", true},
+		{"prompt", "old", true},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "users.go")
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		logger := new(testLogger)
+		if err := writeToFile(bytes.NewBufferString("new"), path, options{overwrite: test.overwrite, logger: logger}); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != test.want {
+			t.Errorf("-overwrite %s: the file is %q, want %q", test.overwrite, content, test.want)
+		}
+		if skipped := len(logger.messages) == 1 && strings.Contains(logger.messages[0], "already exists"); skipped != test.skipped {
+			t.Errorf("-overwrite %s: logged %q, want skipped %t", test.overwrite, logger.messages, test.skipped)
+		}
+	}
+}
+
+func TestGetTagAutoTime(t *testing.T) {
+	options, err := newOptions("postgres", Config{Tags: []string{"gorm"}, AutoTimes: []string{"Created_At:create", "updated_at:update"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		tag             string
+	}{
+		{FieldDescriptor{Name: "created_at", Type: "timestamp"}, `gorm:"column:created_at;autoCreateTime"`},
+		{FieldDescriptor{Name: "UPDATED_AT", Type: "timestamp"}, `gorm:"column:UPDATED_AT;autoUpdateTime"`},
+		{FieldDescriptor{Name: "deleted_at", Type: "timestamp"}, `gorm:"column:deleted_at"`},
+	}
+	for _, test := range tests {
+		if tag := getTag(test.fieldDescriptor, "time.Time", options); tag != "`"+test.tag+"`" {
+			t.Errorf("getTag(%s) = %s, want `%s`", test.fieldDescriptor.Name, tag, test.tag)
+		}
+	}
+
+	if _, err = newOptions("postgres", Config{AutoTimes: []string{"created_at:insert"}}); err == nil {
+		t.Error("newOptions() accepted the auto time created_at:insert")
+	}
+}
+
+func TestEmptyInterface(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	tests := []struct {
+		emptyInterface string
+		want           string
+	}{
+		{"", "func (m *Users) Values() []any {\n\treturn []any{m.Id, m.Name}\n}"},
+		{"any", "func (m *Users) ScanDest() []any {\n\treturn []any{&m.Id, &m.Name}\n}"},
+		{"interface{}", "func (m *Users) Values() []interface{} {\n\treturn []interface{}{m.Id, m.Name}\n}"},
+	}
+	for _, test := range tests {
+		file := generateTestFiles(t, dataSourceName, Config{SQLHelpers: true, EmptyInterface: test.emptyInterface})["users.go"]
+		if !strings.Contains(file, test.want) {
+			t.Errorf("-emptyinterface %q: users.go = %q, want %q", test.emptyInterface, file, test.want)
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{EmptyInterface: "interface"}); err == nil {
+		t.Error("newOptions() accepted the empty interface interface")
+	}
+}
+
+func TestPerPackage(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE order_items (id INTEGER PRIMARY KEY)",
+		`CREATE TABLE "type" (id INTEGER PRIMARY KEY)`,
+	)
+	files := generateTestFiles(t, dataSourceName, Config{PerPackage: true})
+	tests := []struct {
+		path        string
+		packageName string
+		tableName   string
+	}{
+		{"users/users.go", "users", "users"},
+		{"order_items/order_items.go", "order_items", "order_items"},
+		{"type/type.go", "type_", "type"},
+	}
+	if len(files) != len(tests) {
+		t.Errorf("-perpackage generated %d files, want %d", len(files), len(tests))
+	}
+	for _, test := range tests {
+		file, ok := files[test.path]
+		if !ok {
+			t.Errorf("-perpackage didn't generate %s", test.path)
+			continue
+		}
+		if !strings.Contains(file, "\npackage "+test.packageName+" \n") || !strings.Contains(file, "TableName() string {\n\treturn \""+test.tableName+"\"\n}") {
+			t.Errorf("%s = %q, want the package %s of the table %s", test.path, file, test.packageName, test.tableName)
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{PerPackage: true, Relations: true}); err == nil {
+		t.Error("newOptions() accepted -perpackage with -relations")
+	}
+}
+
+func TestGetTypeFloat32(t *testing.T) {
+	tests := []struct {
+		driverName string
+		fieldType  string
+		float32    bool
+		goType     string
+	}{
+		{"mysql", "float", false, "float64"},
+		{"mysql", "float", true, "float32"},
+		{"postgres", "real", false, "float64"},
+		{"postgres", "real", true, "float32"},
+		{"mysql", "double", true, "float64"},
+		{"postgres", "double precision", true, "float64"},
+		{"mysql", "decimal", true, "float64"},
+		{"postgres", "numeric", true, "string"},
+		{"sqlserver", "float", true, "float64"},
+		{"sqlserver", "real", true, "float32"},
+		{"sqlite3", "real", true, "float64"},
+	}
+	for _, test := range tests {
+		goType := testGoType(t, test.driverName, Config{Float32: test.float32}, FieldDescriptor{Name: "value", Type: test.fieldType})
+		if goType != test.goType {
+			t.Errorf("%s: getType(%s, -float32=%t) = %s, want %s", test.driverName, test.fieldType, test.float32, goType, test.goType)
+		}
+	}
+}
+
+func TestPostProcess(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY)",
+	)
+	appendTableConst := func(tableName string, src []byte) ([]byte, error) {
+		return append(src, fmt.Sprintf("\nconst %sTable = %q\n", tableName, tableName)...), nil
+	}
+	files := generateTestFiles(t, dataSourceName, Config{PostProcess: appendTableConst})
+	for _, tableName := range []string{"users", "posts"} {
+		if file, want := files[tableName+".go"], fmt.Sprintf("\nconst %sTable = %q\n", tableName, tableName); !strings.HasSuffix(file, want) {
+			t.Errorf("%s.go = %q, want it to end with %q", tableName, file, want)
+		}
+	}
+
+	failPosts := func(tableName string, src []byte) ([]byte, error) {
+		if tableName == "posts" {
+			return nil, errors.New("rejected")
+		}
+		return src, nil
+	}
+	output := t.TempDir()
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, PostProcess: failPosts, KeepGoing: true, Logger: discardLogger{}})
+	var tableErrors TableErrors
+	if !errors.As(err, &tableErrors) || len(tableErrors) != 1 || tableErrors[0].TableName != "posts" || !strings.Contains(err.Error(), "post process: rejected") {
+		t.Errorf("GenerateWithConfig() = %v, want the post process error of posts", err)
+	}
+	if files := readTestFiles(t, output); len(files) != 1 || files["users.go"] == "" {
+		t.Errorf("the failed post process generated %d files, want users.go only", len(files))
+	}
+}
+
+func TestExcludeColumns(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, row_hash TEXT, avatar BLOB)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT, ROW_HASH TEXT, avatar BLOB)",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{ExcludeColumns: []string{"*.row_hash", "Users.avatar"}, SQLHelpers: true})
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"users.go", "var UsersColumns = []string{\"id\", \"name\"}\n"},
+		{"posts.go", "var PostsColumns = []string{\"id\", \"title\", \"avatar\"}\n"},
+	}
+	for _, test := range tests {
+		file := files[test.fileName]
+		if !strings.Contains(file, test.want) || strings.Contains(strings.ToLower(file), "rowhash") {
+			t.Errorf("%s = %q, want %q without row_hash", test.fileName, file, test.want)
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{ExcludeColumns: []string{"row_hash"}}); err == nil {
+		t.Error("newOptions() accepted the excluded column row_hash without a table")
+	}
+}
+
+func TestCommentTrimAndNoComments(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"users": {
+		{Name: "nick", Type: "text", Comment: "@deprecated; use name"},
+		{Name: "price", Type: "decimal", Precision: 12, Scale: 2, Comment: "price in cents"},
+	}}}
+	tests := []struct {
+		config  Config
+		want    []string
+		notWant []string
+	}{
+		{Config{CommentTrim: `@deprecated;\s*`}, []string{"\t// use name\n\tNick string ``\n", "\t// price in cents\n\tPrice float64 `` // decimal(12,2)\n"}, []string{"@deprecated"}},
+		{Config{NoComments: true}, []string{"\tNick string ``\n\tPrice float64 ``\n}"}, []string{"use name", "price in cents", "decimal(12,2)"}},
+	}
+	for _, test := range tests {
+		test.config.Output = t.TempDir()
+		test.config.Logger = discardLogger{}
+		if err := GenerateWithFetcher(schemaFetcher, test.config); err != nil {
+			t.Fatal(err)
+		}
+		file := readTestFiles(t, test.config.Output)["users.go"]
+		for _, want := range test.want {
+			if !strings.Contains(file, want) {
+				t.Errorf("%+v: users.go = %q, want %q", test.config, file, want)
+			}
+		}
+		for _, notWant := range test.notWant {
+			if strings.Contains(file, notWant) {
+				t.Errorf("%+v: users.go = %q, want no %q", test.config, file, notWant)
+			}
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{CommentTrim: "("}); err == nil {
+		t.Error("newOptions() accepted the comment trim (")
+	}
+}
+
+func TestTagOrder(t *testing.T) {
+	fieldDescriptor := FieldDescriptor{Name: "id", Type: "int", PrimaryKeyOrdinal: 1}
+	want := "`" + `gorm:"column:id;primaryKey" json:"id" db:"id"` + "`"
+	for _, tags := range [][]string{
+		{"gorm", "json", "db"},
+		{"db", "json", "gorm"},
+		{"json", "db", "gorm", "json"},
+	} {
+		options, err := newOptions("postgres", Config{Tags: tags})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag := getTag(fieldDescriptor, "int32", options); tag != want {
+			t.Errorf("-tag %s: getTag() = %s, want %s", strings.Join(tags, ","), tag, want)
+		}
+	}
+}
+
+func TestDBTag(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL)")
+	file := generateTestFiles(t, dataSourceName, Config{Tags: []string{"db"}})["users.go"]
+	for _, want := range []string{"\tId int64 `db:\"id\"`\n", "\tCreatedAt time.Time `db:\"created_at\"`\n"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("-tag db: users.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestPGTag(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	file := generateTestFiles(t, dataSourceName, Config{Tags: []string{"pg", "json"}})["users.go"]
+	for _, want := range []string{"\tId int64 `json:\"id\" pg:\"id,pk\"`\n", "\tName *string `json:\"name\" pg:\"name\"`\n"} {
+		if !strings.Contains(file, want) {
+			t.Errorf("-tag pg: users.go = %q, want %q", file, want)
+		}
+	}
+}
+
+func TestNoTables(t *testing.T) {
+	dataSourceName := newTestDatabase(t)
+	output := t.TempDir()
+	logger := new(testLogger)
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, Logger: logger})
+	if !errors.Is(err, ErrNoTables) || err.Error() != "no tables found in database app" {
+		t.Errorf("GenerateWithConfig() = %v, want ErrNoTables", err)
+	}
+	if len(logger.messages) != 1 || logger.messages[0] != "warning: no tables found in database app" {
+		t.Errorf("GenerateWithConfig() logged %q, want the warning of the empty database", logger.messages)
+	}
+	if files := readTestFiles(t, output); len(files) != 0 {
+		t.Errorf("GenerateWithConfig() generated %d files from the empty database", len(files))
+	}
+}
+
+func TestNoTableName(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE VIEW user_names AS SELECT id, name FROM users",
+		"CREATE VIEW user_ids AS SELECT id FROM users WHERE name IS NOT NULL",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{Views: true, NoTableName: []string{"user_*"}})
+	if file := files["users.go"]; !strings.Contains(file, "func (m Users) TableName() string {\n\treturn \"users\"\n}") {
+		t.Errorf("users.go = %q, want its TableName method", file)
+	}
+	for _, fileName := range []string{"user_names.go", "user_ids.go"} {
+		if file := files[fileName]; !strings.Contains(file, "struct {") || strings.Contains(file, "TableName()") {
+			t.Errorf("%s = %q, want no TableName method", fileName, file)
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{NoTableName: []string{"user_["}}); err == nil {
+		t.Error("newOptions() accepted the pattern user_[")
+	}
+}
+
+func TestNoDatabaseSelected(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{tables: map[string][]FieldDescriptor{"users": {{Name: "id", Type: "int"}}}}
+	err := GenerateWithFetcher(schemaFetcher, Config{Output: t.TempDir(), Logger: discardLogger{}})
+	if err == nil || !strings.Contains(err.Error(), "no database selected") {
+		t.Errorf("GenerateWithFetcher() = %v, want no database selected", err)
+	}
+
+	output := t.TempDir()
+	if err = GenerateWithFetcher(schemaFetcher, Config{Output: output, Package: "models", Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	if file := readTestFiles(t, output)["users.go"]; !strings.Contains(file, "\npackage models \n") {
+		t.Errorf("users.go = %q, want the package models", file)
+	}
+}
+
+func TestMaxTables(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY)",
+	)
+	output := t.TempDir()
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, MaxTables: 2, Logger: discardLogger{}})
+	if err == nil || err.Error() != "found 3 tables in database app, more than -maxtables 2, give them with -t" {
+		t.Errorf("GenerateWithConfig() = %v, want the -maxtables guard", err)
+	}
+	if files := readTestFiles(t, output); len(files) != 0 {
+		t.Errorf("the -maxtables guard generated %d files", len(files))
+	}
+
+	// the tables given with -t are generated whatever their number
+	logger := new(testLogger)
+	files := generateTestFiles(t, dataSourceName, Config{MaxTables: 2, Tables: []string{"users", "posts", "tags"}, Logger: logger})
+	if len(files) != 3 {
+		t.Errorf("-t generated %d files, want 3", len(files))
+	}
+	if len(logger.messages) == 0 || logger.messages[len(logger.messages)-1] != "Generating tags (3/3)" {
+		t.Errorf("logged %q, want the progress of tags", logger.messages)
+	}
+
+	if _, err = newOptions("sqlite3", Config{MaxTables: -1}); err == nil {
+		t.Error("newOptions() accepted -maxtables -1")
+	}
+}
+
+func TestNullableSQLTime(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"events": {
+		{Name: "created_at", Type: "timestamp"},
+		{Name: "deleted_at", Type: "timestamp", AllowNull: true},
+	}}}
+	tests := []struct {
+		nullable string
+		want     []string
+	}{
+		{"", []string{"import \"time\"\n", "\tDeletedAt *time.Time ``\n"}},
+		{"sql", []string{"import (\n\t\"database/sql\"\n\t\"time\"\n)\n", "\tCreatedAt time.Time ``\n", "\tDeletedAt sql.NullTime ``\n"}},
+	}
+	for _, test := range tests {
+		output := t.TempDir()
+		if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, Nullable: test.nullable, Logger: discardLogger{}}); err != nil {
+			t.Fatal(err)
+		}
+		file := readTestFiles(t, output)["events.go"]
+		for _, want := range test.want {
+			if !strings.Contains(file, want) {
+				t.Errorf("-nullable %q: events.go = %q, want %q", test.nullable, file, want)
+			}
+		}
+	}
+}
+
+func TestNullPointer(t *testing.T) {
+	tests := []struct {
+		nullPointer []string
+		fieldType   string
+		goType      string
+	}{
+		{[]string{"string"}, "integer", "sql.NullInt64"},
+		{[]string{"string"}, "text", "*string"},
+		{[]string{"int"}, "integer", "*int64"},
+		{[]string{"int"}, "text", "sql.NullString"},
+		{[]string{"int", "time"}, "timestamp", "*time.Time"},
+	}
+	for _, test := range tests {
+		goType := testGoType(t, "postgres", Config{NullPointer: test.nullPointer}, FieldDescriptor{Name: "value", Type: test.fieldType, AllowNull: true})
+		if goType != test.goType {
+			t.Errorf("-nullpointer %s: getType(%s) = %s, want %s", strings.Join(test.nullPointer, ","), test.fieldType, goType, test.goType)
+		}
+	}
+
+	for _, config := range []Config{{NullPointer: []string{"decimal"}}, {NullPointer: []string{"int"}, Nullable: "pointer"}} {
+		if _, err := newOptions("postgres", config); err == nil {
+			t.Errorf("newOptions() accepted %+v", config)
+		}
+	}
+}
+
+func TestFailedToConnect(t *testing.T) {
+	// nothing listens on port 1
+	dataSourceName := "app:secret@tcp(127.0.0.1:1)/shop?timeout=1s"
+	err := GenerateWithConfig("mysql", Config{Output: t.TempDir(), DataSourceName: dataSourceName, Logger: discardLogger{}})
+	if err == nil || !strings.HasPrefix(err.Error(), "failed to connect to mysql database ") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("GenerateWithConfig() = %v, want the connection error without the password", err)
+	}
+}
+
+func TestWriterFor(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT)",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{})
+	output := t.TempDir()
+	writers := make(testWriters)
+	if err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, WriterFor: writers.writerFor, Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	if len(writers) != 2 {
+		t.Errorf("WriterFor got %d writers, want users and posts", len(writers))
+	}
+	for _, tableName := range []string{"users", "posts"} {
+		if w, file := writers[tableName], files[tableName+".go"]; w == nil || w.String() != file {
+			t.Errorf("the writer of %s got %v, want %q", tableName, w, file)
+		}
+	}
+	if files := readTestFiles(t, output); len(files) != 0 {
+		t.Errorf("WriterFor created %d files", len(files))
+	}
+
+	failing := func(name string) (io.WriteCloser, error) {
+		return nil, errors.New("no space left")
+	}
+	err := GenerateWithConfig("sqlite3", Config{Output: output, DataSourceName: dataSourceName, WriterFor: failing, Logger: discardLogger{}})
+	if err == nil || !strings.Contains(err.Error(), "no space left") {
+		t.Errorf("GenerateWithConfig() = %v, want the error of WriterFor", err)
+	}
+}
+
+func TestGetTypePostgresArrays(t *testing.T) {
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		goType          string
+	}{
+		{FieldDescriptor{Name: "names", Type: "character varying[]"}, "pq.StringArray"},
+		{FieldDescriptor{Name: "names", Type: "varchar[]", AllowNull: true}, "pq.StringArray"},
+		{FieldDescriptor{Name: "times", Type: "timestamp[]"}, "[]time.Time"},
+		{FieldDescriptor{Name: "times", Type: "timestamp with time zone[]", AllowNull: true}, "[]time.Time"},
+		{FieldDescriptor{Name: "prices", Type: "numeric[]"}, "pq.StringArray"},
+		{FieldDescriptor{Name: "ids", Type: "uuid[]"}, "pq.StringArray"},
+		{FieldDescriptor{Name: "matrix", Type: "integer[][]"}, "pq.Int64Array"},
+	}
+	for _, test := range tests {
+		if goType := testGoType(t, "postgres", Config{}, test.fieldDescriptor); goType != test.goType {
+			t.Errorf("getType(%s) = %s, want %s", test.fieldDescriptor.Type, goType, test.goType)
+		}
+	}
+
+	output := t.TempDir()
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"grids": {{Name: "cells", Type: "varchar[][]"}}}}
+	if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	if file, want := readTestFiles(t, output)["grids.go"], "\tCells pq.StringArray `` // 2-dimensional array\n"; !strings.Contains(file, want) {
+		t.Errorf("grids.go = %q, want %q", file, want)
+	}
+}
+
+// generatedCodeRegexp matches the lines marking generated files, see https://go.dev/s/generatedcode.
+var generatedCodeRegexp = regexp.MustCompile(`^// Code generated .* DO NOT EDIT\.$`)
+
+func TestMarker(t *testing.T) {
+	if !generatedCodeRegexp.MatchString(defaultMarker) {
+		t.Errorf("the default marker %q isn't recognized by Go tools", defaultMarker)
+	}
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	tests := []struct {
+		marker string
+		want   string
+	}{
+		{"", defaultMarker + "\npackage app \n"},
+		{"Code generated by acme; DO NOT EDIT.", "// Code generated by acme; DO NOT EDIT.\npackage app \n"},
+		{"// Generated by acme", "// Generated by acme\npackage app \n"},
+	}
+	for _, test := range tests {
+		if file := generateTestFiles(t, dataSourceName, Config{Marker: test.marker})["users.go"]; !strings.HasPrefix(file, test.want) {
+			t.Errorf("-marker %q: users.go = %q, want it to start with %q", test.marker, file, test.want)
+		}
+	}
+
+	if _, err := newOptions("sqlite3", Config{Marker: "Code generated\nDO NOT EDIT."}); err == nil {
+		t.Error("newOptions() accepted a marker of two lines")
+	}
+}
@@ -0,0 +1,36 @@
+package generator
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitAttributesEntry marks the generated files of the output path as generated for GitHub and
+// collapses their diffs.
+const gitAttributesEntry = "*.go linguist-generated=true -diff"
+
+// writeGitAttributes adds gitAttributesEntry to the .gitattributes file of the output path,
+// keeping the entries already in it. As the existing file is kept, it is written whatever -overwrite
+// is, which would otherwise skip it when it exists.
+func writeGitAttributes(options options) error {
+	outputFile := filepath.Join(options.outputPath, ".gitattributes")
+	existing, err := os.ReadFile(outputFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for _, line := range strings.Split(string(existing), "\n") {
+		if strings.TrimSpace(line) == gitAttributesEntry {
+			return nil
+		}
+	}
+	var buf bytes.Buffer
+	buf.Write(existing)
+	if len(existing) != 0 && !bytes.HasSuffix(existing, []byte("\n")) {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(gitAttributesEntry + "\n")
+	options.overwrite = "always"
+	return writeOutputFile(&buf, outputFile, "", options)
+}
@@ -0,0 +1,46 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteGitAttributes(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  string
+		overwrite string
+		want      string
+	}{
+		{"new", "", "", gitAttributesEntry + "\n"},
+		{"append", "*.png binary\n", "prompt", "*.png binary\n" + gitAttributesEntry + "\n"},
+		{"append without trailing newline", "*.png binary", "never", "*.png binary\n" + gitAttributesEntry + "\n"},
+		{"already present", "*.png binary\n" + gitAttributesEntry + "\n", "always", "*.png binary\n" + gitAttributesEntry + "\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			outputFile := filepath.Join(dir, ".gitattributes")
+			if test.existing != "" {
+				if err := os.WriteFile(outputFile, []byte(test.existing), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+			options, err := newOptions("sqlite3", Config{Output: dir, Overwrite: test.overwrite, Logger: discardLogger{}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = writeGitAttributes(options); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Errorf(".gitattributes = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
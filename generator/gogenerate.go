@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// goGenerateFileName is the name, without extension, of the file holding the directive of -gogenerate.
+const goGenerateFileName = "sqlmodel_generate"
+
+// generatorCommands maps the driver names to the packages of their commands, run by the directive of
+// -gogenerate.
+var generatorCommands = map[string]string{
+	"mysql":      "github.com/Ficoto/sqlmodel/sqlm-gen-mysql",
+	"sqlite3":    "github.com/Ficoto/sqlmodel/sqlm-gen-sqlite3",
+	"postgres":   "github.com/Ficoto/sqlmodel/sqlm-gen-postgres",
+	"sqlserver":  "github.com/Ficoto/sqlmodel/sqlm-gen-sqlserver",
+	"mssql":      "github.com/Ficoto/sqlmodel/sqlm-gen-sqlserver",
+	"clickhouse": "github.com/Ficoto/sqlmodel/sqlm-gen-clickhouse",
+}
+
+// goGeneratePathFlags are the flags giving paths, which are relative to the working directory of the
+// command but are given to the directive relative to the output path, where go generate runs it.
+var goGeneratePathFlags = []string{"template", "proto", "manifest", "header", "tablesfile", "tlsca", "tlscert", "tlskey"}
+
+// goGenerateDirective returns the //go:generate directive of -gogenerate, running the command of
+// driverName with the flags of the fields set in config from outputPath, the directory of the
+// generated package. The data source names are left out so that no password is written, the
+// command reads them from $SQLMODEL_DSN instead. As go generate doesn't connect stdin, where the
+// default -overwrite prompt would keep the existing files, the directive overwrites them unless
+// config gives -overwrite. ok is false if the driver has no command.
+func goGenerateDirective(driverName string, config Config, outputPath string) (directive string, ok bool, err error) {
+	command, ok := generatorCommands[driverName]
+	if !ok {
+		return
+	}
+	if config.Overwrite == "" {
+		config.Overwrite = "always"
+	}
+	args := []string{"//go:generate", "go", "run", command, "-o", "."}
+	configType := reflect.TypeOf(config)
+	configValue := reflect.ValueOf(config)
+	for i := 0; i < configType.NumField(); i++ {
+		name := configType.Field(i).Tag.Get("flag")
+		value := configValue.Field(i)
+		switch name {
+		case "", "o", "dbc", "gogenerate", "dryrun":
+			continue
+		}
+		if value.IsZero() {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.Bool:
+			args = append(args, "-"+name)
+		case reflect.Slice:
+			args = append(args, "-"+name, quoteGoGenerateArg(strings.Join(value.Interface().([]string), ",")))
+		default:
+			arg := fmt.Sprint(value.Interface())
+			if containsString(goGeneratePathFlags, name) {
+				if arg, err = relativePath(outputPath, arg); err != nil {
+					return
+				}
+			}
+			args = append(args, "-"+name, quoteGoGenerateArg(arg))
+		}
+	}
+	return strings.Join(args, " "), true, nil
+}
+
+// relativePath returns the path, relative to the working directory, relative to dir instead.
+func relativePath(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	relPath, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relPath), nil
+}
+
+// quoteGoGenerateArg quotes an argument of a //go:generate directive if go generate would split it.
+func quoteGoGenerateArg(arg string) string {
+	if arg == "" || strings.ContainsAny(arg, " \t\"") {
+		return strconv.Quote(arg)
+	}
+	return arg
+}
+
+// writeGoGenerateFile writes the file of the output path holding the directive of -gogenerate, once
+// for the package rather than in each file, so that go generate runs the command once.
+func writeGoGenerateFile(dbName string, options options) error {
+	directive, ok, err := goGenerateDirective(options.driverName, *options.goGenerate, options.outputPath)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		options.logger.Printf("warning: -gogenerate has no command for driver %q, no directive is written", options.driverName)
+		return nil
+	}
+	return writeSource(dbName, "", goGenerateFileName, nil, []byte(directive+"\n"), options)
+}
@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGoGenerateDirective(t *testing.T) {
+	chdirTemp(t)
+	if err := os.WriteFile("header.txt", []byte("// Copyright\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)", "CREATE TABLE posts (id INTEGER PRIMARY KEY)")
+	output := filepath.Join("models", "app")
+	if err := os.MkdirAll(output, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := generateTestFiles(t, dataSourceName, Config{
+		Output:     output,
+		GoGenerate: true,
+		Header:     "header.txt",
+		Tags:       []string{"json", "gorm"},
+	})
+
+	directive := "//go:generate go run github.com/Ficoto/sqlmodel/sqlm-gen-sqlite3 -o . -overwrite always -tag json,gorm -header ../../header.txt\n"
+	if file := files[goGenerateFileName+".go"]; !strings.Contains(file, directive) {
+		t.Errorf("%s.go = %q, want the directive %q", goGenerateFileName, file, directive)
+	}
+	for name, file := range files {
+		if name != goGenerateFileName+".go" && strings.Contains(file, "go:generate") {
+			t.Errorf("%s has a go:generate directive", name)
+		}
+	}
+}
+
+func TestGoGenerateDirectiveLeavesOutDataSourceNames(t *testing.T) {
+	directive, ok, err := goGenerateDirective("mysql", Config{
+		Output:          "models",
+		DataSourceName:  "user:secret@tcp(localhost:3306)/app",
+		DataSourceNames: []string{"user:secret@tcp(localhost:3306)/other"},
+		GoGenerate:      true,
+	}, "models")
+	if err != nil || !ok {
+		t.Fatalf("goGenerateDirective() = %v, %v", ok, err)
+	}
+	if want := "//go:generate go run github.com/Ficoto/sqlmodel/sqlm-gen-mysql -o . -overwrite always"; directive != want {
+		t.Errorf("goGenerateDirective() = %q, want %q", directive, want)
+	}
+}
+
+func TestGoGenerateDirectiveOverwrite(t *testing.T) {
+	tests := []struct {
+		overwrite string
+		want      string
+	}{
+		{"", " -overwrite always"},
+		{"always", " -overwrite always"},
+		{"never", " -overwrite never"},
+		{"prompt", " -overwrite prompt"},
+	}
+	for _, test := range tests {
+		directive, _, err := goGenerateDirective("sqlite3", Config{Overwrite: test.overwrite}, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasSuffix(directive, test.want) {
+			t.Errorf("-overwrite %q: goGenerateDirective() = %q, want %q", test.overwrite, directive, test.want)
+		}
+	}
+
+	// running the directive of a default config again overwrites the stale models
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	output := t.TempDir()
+	files := generateTestFiles(t, dataSourceName, Config{Output: output, GoGenerate: true})
+	users := filepath.Join(output, "users.go")
+	if err := os.WriteFile(users, []byte("package app\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	directive, _, err := goGenerateDirective("sqlite3", Config{}, output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := Config{Output: output}
+	args := strings.Fields(strings.TrimPrefix(directive, "//go:generate go run github.com/Ficoto/sqlmodel/sqlm-gen-sqlite3 -o ."))
+	if len(args) != 2 || args[0] != "-overwrite" {
+		t.Fatalf("goGenerateDirective() = %q, want -overwrite only", directive)
+	}
+	config.Overwrite = args[1]
+	generateTestFiles(t, dataSourceName, config)
+	if written := readTestFiles(t, output); written["users.go"] != files["users.go"] {
+		t.Errorf("the directive wrote users.go %q, want %q", written["users.go"], files["users.go"])
+	}
+}
+
+func TestGoGenerateDirectiveCommands(t *testing.T) {
+	tests := []struct {
+		driverName string
+		command    string
+	}{
+		{"mysql", "github.com/Ficoto/sqlmodel/sqlm-gen-mysql"},
+		{"postgres", "github.com/Ficoto/sqlmodel/sqlm-gen-postgres"},
+		{"sqlserver", "github.com/Ficoto/sqlmodel/sqlm-gen-sqlserver"},
+		{"mssql", "github.com/Ficoto/sqlmodel/sqlm-gen-sqlserver"},
+		{"clickhouse", "github.com/Ficoto/sqlmodel/sqlm-gen-clickhouse"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		directive, ok, err := goGenerateDirective(test.driverName, Config{}, ".")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := test.command != ""; ok != want {
+			t.Errorf("goGenerateDirective(%q) ok = %v, want %v", test.driverName, ok, want)
+		}
+		if ok && !strings.Contains(directive, " "+test.command+" ") {
+			t.Errorf("goGenerateDirective(%q) = %q, want command %s", test.driverName, directive, test.command)
+		}
+	}
+}
+
+func TestGoGenerateWithStdout(t *testing.T) {
+	if _, err := newOptions("sqlite3", Config{GoGenerate: true, Stdout: true}); err == nil {
+		t.Error("newOptions() succeeded with -gogenerate and -stdout")
+	}
+}
@@ -0,0 +1,86 @@
+package generator
+
+import (
+	"strings"
+	"unicode"
+)
+
+var uncountableWords = []string{
+	"audio", "data", "equipment", "feedback", "fish", "information", "metadata", "money", "news", "series", "sheep", "species",
+}
+
+var irregularPlurals = map[string]string{
+	"buses":    "bus",
+	"children": "child",
+	"feet":     "foot",
+	"geese":    "goose",
+	"men":      "man",
+	"mice":     "mouse",
+	"oxen":     "ox",
+	"people":   "person",
+	"pies":     "pie",
+	"teeth":    "tooth",
+	"ties":     "tie",
+	"women":    "woman",
+}
+
+// singularSuffixes are tried in order, the words whose plurals don't follow the general rules come
+// first.
+var singularSuffixes = []struct {
+	plural   string
+	singular string
+}{
+	{"cookies", "cookie"},
+	{"movies", "movie"},
+	{"zombies", "zombie"},
+	{"calories", "calorie"},
+	{"rookies", "rookie"},
+	{"selfies", "selfie"},
+	{"ies", "y"},
+	{"sses", "ss"},
+	{"statuses", "status"},
+	{"bonuses", "bonus"},
+	{"campuses", "campus"},
+	{"censuses", "census"},
+	{"viruses", "virus"},
+	{"uses", "use"},
+	{"xes", "x"},
+	{"caches", "cache"},
+	{"niches", "niche"},
+	{"headaches", "headache"},
+	{"ches", "ch"},
+	{"shes", "sh"},
+	{"quizzes", "quiz"},
+	{"zzes", "zz"},
+	{"ss", "ss"},
+	{"us", "us"},
+	{"is", "is"},
+	{"s", ""},
+}
+
+// singularize returns the singular form of the last word of name.
+func singularize(name string) string {
+	i := strings.LastIndexFunc(name, func(r rune) bool { return !unicode.IsLetter(r) }) + 1
+	return name[:i] + singularizeWord(name[i:])
+}
+
+func singularizeWord(word string) string {
+	lower := strings.ToLower(word)
+	for _, uncountable := range uncountableWords {
+		if strings.HasSuffix(lower, uncountable) {
+			return word
+		}
+	}
+	if singular, ok := irregularPlurals[lower]; ok {
+		if word != lower {
+			singular = strings.ToUpper(singular[:1]) + singular[1:]
+		}
+		return singular
+	}
+	for _, suffix := range singularSuffixes {
+		if strings.HasSuffix(lower, suffix.plural) {
+			return word[:len(word)-len(suffix.plural)] + suffix.singular
+		}
+	}
+	return word
+}
@@ -0,0 +1,71 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSingularize(t *testing.T) {
+	tests := []struct {
+		name     string
+		singular string
+	}{
+		{"users", "user"},
+		{"categories", "category"},
+		{"people", "person"},
+		{"audit", "audit"},
+		{"addresses", "address"},
+		{"boxes", "box"},
+		{"branches", "branch"},
+		{"wishes", "wish"},
+		{"quizzes", "quiz"},
+		{"buzzes", "buzz"},
+		{"houses", "house"},
+		{"courses", "course"},
+		{"warehouses", "warehouse"},
+		{"causes", "cause"},
+		{"statuses", "status"},
+		{"order_statuses", "order_status"},
+		{"buses", "bus"},
+		{"abuses", "abuse"},
+		{"status", "status"},
+		{"caches", "cache"},
+		{"page_caches", "page_cache"},
+		{"matches", "match"},
+		{"cookies", "cookie"},
+		{"movies", "movie"},
+		{"user_movies", "user_movie"},
+		{"pies", "pie"},
+		{"copies", "copy"},
+		{"analysis", "analysis"},
+		{"news", "news"},
+		{"user_metadata", "user_metadata"},
+		{"children", "child"},
+		{"Children", "Child"},
+		{"UserHouses", "UserHouse"},
+	}
+	for _, test := range tests {
+		if singular := singularize(test.name); singular != test.singular {
+			t.Errorf("singularize(%q) = %q, want %q", test.name, singular, test.singular)
+		}
+	}
+}
+
+func TestSingularizeStructName(t *testing.T) {
+	options, err := newOptions("sqlite3", Config{Singularize: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tableName, structName := range map[string]string{"users": "User", "warehouses": "Warehouse", "cookies": "Cookie"} {
+		if name := getStructName(tableName, options); name != structName {
+			t.Errorf("getStructName(%q) = %q, want %q", tableName, name, structName)
+		}
+	}
+
+	// TableName keeps the plural name of the table
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY)")
+	file := generateTestFiles(t, dataSourceName, Config{Singularize: true})["users.go"]
+	if !strings.Contains(file, "type User struct {") || !strings.Contains(file, "func (m User) TableName() string {\n\treturn \"users\"\n}") {
+		t.Errorf("users.go = %q, want struct User of table users", file)
+	}
+}
@@ -0,0 +1,51 @@
+package generator
+
+import "database/sql"
+
+// Logger receives the progress messages and warnings of the generation, *log.Logger implements it.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// queryer is the part of *sql.DB used by the schema fetchers.
+type queryer interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// loggingQueryer logs the queries of the schema fetchers with -v.
+type loggingQueryer struct {
+	db     *sql.DB
+	logger Logger
+}
+
+func (q loggingQueryer) log(query string, args []interface{}) {
+	if len(args) == 0 {
+		q.logger.Printf("query: %s", query)
+	} else {
+		q.logger.Printf("query: %s %v", query, args)
+	}
+}
+
+func (q loggingQueryer) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	q.log(query, args)
+	return q.db.Query(query, args...)
+}
+
+func (q loggingQueryer) QueryRow(query string, args ...interface{}) *sql.Row {
+	q.log(query, args)
+	return q.db.QueryRow(query, args...)
+}
+
+func newQueryer(db *sql.DB, options options) queryer {
+	if options.verbose {
+		return loggingQueryer{db: db, logger: options.logger}
+	}
+	return db
+}
+
+func logFieldType(tableName string, fieldDescriptor FieldDescriptor, goType string, options options) {
+	if options.verbose {
+		options.logger.Printf("%s.%s: %s -> %s", tableName, fieldDescriptor.Name, fieldDescriptor.Type, goType)
+	}
+}
@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// testLogger collects the messages of the generation.
+type testLogger struct {
+	messages []string
+}
+
+func (l *testLogger) Printf(format string, v ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, v...))
+}
+
+func TestVerboseAndQuiet(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	tests := []struct {
+		config   Config
+		want     []string
+		dontWant []string
+	}{
+		{Config{}, []string{"Generating users (1/1)"}, []string{"query: ", "users.name: "}},
+		{Config{Verbose: true}, []string{"Generating users (1/1)", "query: SELECT", "[users]", "users.name: TEXT -> *string"}, nil},
+		{Config{Quiet: true}, nil, []string{"Generating users"}},
+	}
+	for _, test := range tests {
+		logger := new(testLogger)
+		test.config.Logger = logger
+		generateTestFiles(t, dataSourceName, test.config)
+		messages := strings.Join(logger.messages, "\n")
+		for _, want := range test.want {
+			if !strings.Contains(messages, want) {
+				t.Errorf("-v %v -q %v logged %q, want %q", test.config.Verbose, test.config.Quiet, messages, want)
+			}
+		}
+		for _, dontWant := range test.dontWant {
+			if strings.Contains(messages, dontWant) {
+				t.Errorf("-v %v -q %v logged %q", test.config.Verbose, test.config.Quiet, dontWant)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package generator
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+)
+
+// manifest accumulates the generated tables for the JSON file of -manifest.
+type manifest struct {
+	path   string
+	Tables []manifestTable `json:"tables"`
+}
+
+type manifestTable struct {
+	TableName  string          `json:"table"`
+	StructName string          `json:"struct"`
+	Fields     []manifestField `json:"fields"`
+}
+
+type manifestField struct {
+	Column   string `json:"column"`
+	SQLType  string `json:"sqlType"`
+	GoName   string `json:"goName"`
+	GoType   string `json:"goType"`
+	Nullable bool   `json:"nullable"`
+	Tag      string `json:"tag"`
+}
+
+func (m *manifest) add(tableName, structName string, fields []FieldInfo) {
+	table := manifestTable{TableName: tableName, StructName: structName, Fields: []manifestField{}}
+	for _, field := range fields {
+		table.Fields = append(table.Fields, manifestField{
+			Column:   field.Column,
+			SQLType:  field.SQLType,
+			GoName:   field.Name,
+			GoType:   field.GoType,
+			Nullable: field.Nullable,
+			Tag:      field.Tag,
+		})
+	}
+	m.Tables = append(m.Tables, table)
+}
+
+func (m *manifest) write(options options) error {
+	if m.Tables == nil {
+		m.Tables = []manifestTable{}
+	}
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	buf := bytes.NewBuffer(content)
+	buf.WriteString("\n")
+	return writeOutputFile(buf, m.path, filepath.Base(m.path), options)
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManifest(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, title TEXT NOT NULL)",
+	)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	files := generateTestFiles(t, dataSourceName, Config{Manifest: path, Tags: []string{"json"}})
+	if len(files) != 2 {
+		t.Errorf("generated %d files, want the files of users and posts", len(files))
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got manifest
+	if err = json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("the manifest %s is not valid JSON: %s", content, err)
+	}
+	tables := make(map[string]manifestTable)
+	for _, table := range got.Tables {
+		tables[table.TableName] = table
+	}
+	tests := []struct {
+		tableName  string
+		structName string
+		field      manifestField
+	}{
+		{"users", "Users", manifestField{Column: "name", SQLType: "TEXT", GoName: "Name", GoType: "*string", Nullable: true, Tag: `json:"name"`}},
+		{"posts", "Posts", manifestField{Column: "title", SQLType: "TEXT", GoName: "Title", GoType: "string", Tag: `json:"title"`}},
+	}
+	if len(got.Tables) != len(tests) {
+		t.Errorf("the manifest has %d tables, want %d", len(got.Tables), len(tests))
+	}
+	for _, test := range tests {
+		table := tables[test.tableName]
+		if table.StructName != test.structName || len(table.Fields) != 2 || table.Fields[1] != test.field {
+			t.Errorf("the manifest of %s is %+v, want the struct %s with the field %+v", test.tableName, table, test.structName, test.field)
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// modelField is a field of a generated model.
+type modelField struct {
+	name            string
+	goType          string
+	fieldDescriptor FieldDescriptor
+	// import paths used by goType
+	imports []string
+}
+
+func isBytesType(goType string) bool {
+	switch strings.TrimPrefix(goType, "*") {
+	case "[]byte", "json.RawMessage":
+		return true
+	}
+	return false
+}
+
+// writeStringMethod writes a String method printing the fields like Model{ID: 1, Name: <nil>}. It
+// reports whether the method uses the fmt package, which it does when there are fields to print.
+func writeStringMethod(buf *bytes.Buffer, className string, fields []modelField) (usesFmt bool) {
+	buf.WriteString(fmt.Sprintf("func (m %s) String() string {\n", className))
+	buf.WriteString("\tvar b strings.Builder\n")
+	separator := className + "{"
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\tb.WriteString(%q)\n", separator+field.name+": "))
+		separator = ", "
+		verb := "%v"
+		if isBytesType(field.goType) {
+			verb = "%s"
+		}
+		if strings.HasPrefix(field.goType, "*") {
+			buf.WriteString(fmt.Sprintf("\tif m.%s != nil {\n", field.name))
+			buf.WriteString(fmt.Sprintf("\t\tfmt.Fprintf(&b, %q, *m.%s)\n", verb, field.name))
+			buf.WriteString("\t} else {\n")
+			buf.WriteString("\t\tb.WriteString(\"<nil>\")\n")
+			buf.WriteString("\t}\n")
+		} else {
+			buf.WriteString(fmt.Sprintf("\tfmt.Fprintf(&b, %q, m.%s)\n", verb, field.name))
+		}
+	}
+	if len(fields) == 0 {
+		buf.WriteString(fmt.Sprintf("\tb.WriteString(%q)\n", separator))
+	}
+	buf.WriteString("\tb.WriteString(\"}\")\n")
+	buf.WriteString("\treturn b.String()\n")
+	buf.WriteString("}\n\n")
+	return len(fields) != 0
+}
+
+// writeSQLHelpers writes a variable holding the columns, a Values method returning the fields in
+// the same order, for building INSERT and UPDATE statements, and a ScanDest method returning
+// pointers to them for scanning rows selecting the columns. Generated columns are left out as
+// they cannot be written.
+func writeSQLHelpers(buf *bytes.Buffer, className string, allFields []modelField, emptyInterface string) {
+	var fields []modelField
+	for _, field := range allFields {
+		if !field.fieldDescriptor.IsGenerated {
+			fields = append(fields, field)
+		}
+	}
+	buf.WriteString(fmt.Sprintf("var %sColumns = []string{", className))
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("%q", field.fieldDescriptor.Name))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (m *%s) Values() []%s {\n", className, emptyInterface))
+	buf.WriteString(fmt.Sprintf("\treturn []%s{", emptyInterface))
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("m." + field.name)
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (m *%s) ScanDest() []%s {\n", className, emptyInterface))
+	buf.WriteString(fmt.Sprintf("\treturn []%s{", emptyInterface))
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("&m." + field.name)
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n\n")
+}
+
+// writeQuotedColumns writes a variable holding the columns of writeSQLHelpers quoted by schemaFetcher.
+func writeQuotedColumns(buf *bytes.Buffer, className string, allFields []modelField, schemaFetcher SchemaFetcher) {
+	buf.WriteString(fmt.Sprintf("var %sQuotedColumns = []string{", className))
+	i := 0
+	for _, field := range allFields {
+		if field.fieldDescriptor.IsGenerated {
+			continue
+		}
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(fmt.Sprintf("%q", schemaFetcher.QuoteIdentifier(field.fieldDescriptor.Name)))
+		i++
+	}
+	buf.WriteString("}\n\n")
+}
+
+// writeFieldMap writes a variable mapping the names of the fields to their columns.
+func writeFieldMap(buf *bytes.Buffer, className string, fields []modelField) {
+	buf.WriteString(fmt.Sprintf("var %sFieldColumns = map[string]string{\n", className))
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\t%q: %q,\n", field.name, field.fieldDescriptor.Name))
+	}
+	buf.WriteString("}\n\n")
+}
+
+// getJSONName returns the JSON key of a column.
+func getJSONName(fieldDescriptor FieldDescriptor) string {
+	return fieldDescriptor.Name
+}
+
+// getJSONTag returns the value of the json tag of a column: its JSON key, with the omitempty option
+// for nullable columns with -omitempty, and the string option for 64-bit integers with
+// -jsonstringints as JavaScript numbers cannot hold them.
+func getJSONTag(fieldDescriptor FieldDescriptor, goType string, stringInts, omitEmpty bool) string {
+	tag := getJSONName(fieldDescriptor)
+	if omitEmpty && fieldDescriptor.AllowNull {
+		tag += ",omitempty"
+	}
+	switch strings.TrimPrefix(goType, "*") {
+	case "int64", "uint64":
+		if stringInts {
+			tag += ",string"
+		}
+	}
+	return tag
+}
+
+// writeJSONMethods writes MarshalJSON and UnmarshalJSON methods mapping each column to its JSON
+// key through an unexported struct, so they can be customized without struct tags. It returns the
+// import paths of the types of the fields of the struct, which the file doesn't import yet for
+// promoted fields.
+func writeJSONMethods(buf *bytes.Buffer, className string, fields []modelField, jsonStringInts, omitEmpty bool) (imports []string) {
+	first, size := utf8.DecodeRuneInString(className)
+	jsonType := string(unicode.ToLower(first)) + className[size:] + "JSON"
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", jsonType))
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\t%s %s `json:%q`\n", field.name, field.goType, getJSONTag(field.fieldDescriptor, field.goType, jsonStringInts, omitEmpty)))
+		imports = appendImports(imports, field.imports...)
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (m %s) MarshalJSON() ([]byte, error) {\n", className))
+	buf.WriteString(fmt.Sprintf("\treturn json.Marshal(%s{\n", jsonType))
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\t\t%s: m.%s,\n", field.name, field.name))
+	}
+	buf.WriteString("\t})\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (m *%s) UnmarshalJSON(data []byte) error {\n", className))
+	buf.WriteString(fmt.Sprintf("\tvar v %s\n", jsonType))
+	buf.WriteString("\tif err := json.Unmarshal(data, &v); err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\tm.%s = v.%s\n", field.name, field.name))
+	}
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n\n")
+	return
+}
+
+// writeConstructor writes a New function taking the fields which must be set: the not null columns
+// which are neither auto-increment, generated nor have a default. It returns the import paths of
+// the types of the parameters, which the file doesn't import yet for promoted fields.
+func writeConstructor(buf *bytes.Buffer, className string, fields []modelField) (imports []string) {
+	var parameters []string
+	var assignments strings.Builder
+	for _, field := range fields {
+		fieldDescriptor := field.fieldDescriptor
+		if fieldDescriptor.AllowNull || fieldDescriptor.IsAutoIncrement || fieldDescriptor.IsGenerated || fieldDescriptor.DefaultValue != nil {
+			continue
+		}
+		parameter := getParameterName(field.name)
+		if parameter == "m" {
+			// m is the constructed model
+			parameter = "m_"
+		}
+		parameters = append(parameters, parameter+" "+field.goType)
+		imports = appendImports(imports, field.imports...)
+		assignments.WriteString(fmt.Sprintf("\tm.%s = %s\n", field.name, parameter))
+	}
+	buf.WriteString(fmt.Sprintf("func New%s(%s) %s {\n", className, strings.Join(parameters, ", "), className))
+	buf.WriteString(fmt.Sprintf("\tvar m %s\n", className))
+	buf.WriteString(assignments.String())
+	buf.WriteString("\treturn m\n")
+	buf.WriteString("}\n\n")
+	return
+}
+
+// getParameterName returns the unexported form of a field name, such as userID for UserID or id
+// for ID, suffixed with an underscore if it is a keyword.
+func getParameterName(fieldName string) string {
+	runes := []rune(fieldName)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i != 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			// the upper case letter starts the next word, as the P of URLPath
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	name := string(runes)
+	if token.IsKeyword(name) {
+		name += "_"
+	}
+	return name
+}
+
+// isByteSliceType reports whether goType is a byte slice, including named ones, compared by bytes.Equal.
+func isByteSliceType(goType string) bool {
+	switch goType {
+	case "[]byte", "json.RawMessage", "net.IP", "net.HardwareAddr", "sqlingo.WellKnownBinary":
+		return true
+	}
+	return false
+}
+
+// writeEqualMethod writes an Equal method comparing the fields: pointers by the values they point to,
+// byte slices and networks by bytes.Equal, other slices element by element and times by their
+// Equal method. It returns whether the bytes package is used.
+func writeEqualMethod(buf *bytes.Buffer, className string, fields []modelField) (usesBytes bool) {
+	buf.WriteString(fmt.Sprintf("func (m %s) Equal(other %s) bool {\n", className, className))
+	for _, field := range fields {
+		a, b, goType := "m."+field.name, "other."+field.name, field.goType
+		if isSliceType(goType) && !isByteSliceType(goType) {
+			buf.WriteString(fmt.Sprintf("\tif len(%s) != len(%s) {\n", a, b))
+			buf.WriteString("\t\treturn false\n")
+			buf.WriteString("\t}\n")
+			buf.WriteString(fmt.Sprintf("\tfor i := range %s {\n", a))
+			// the elements of pq arrays are compared by ==
+			equal := getEqualExpression(a+"[i]", b+"[i]", strings.TrimPrefix(goType, "[]"))
+			usesBytes = usesBytes || strings.Contains(equal, "bytes.Equal")
+			buf.WriteString(fmt.Sprintf("\t\tif !(%s) {\n", equal))
+			buf.WriteString("\t\t\treturn false\n")
+			buf.WriteString("\t\t}\n")
+			buf.WriteString("\t}\n")
+			continue
+		}
+		var equal string
+		if strings.HasPrefix(goType, "*") {
+			equal = getEqualExpression("*"+a, "*"+b, goType[1:])
+			equal = fmt.Sprintf("(%s == nil) == (%s == nil) && (%s == nil || %s)", a, b, a, equal)
+		} else {
+			equal = getEqualExpression(a, b, goType)
+		}
+		usesBytes = usesBytes || strings.Contains(equal, "bytes.Equal")
+		buf.WriteString(fmt.Sprintf("\tif !(%s) {\n", equal))
+		buf.WriteString("\t\treturn false\n")
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("\treturn true\n")
+	buf.WriteString("}\n\n")
+	return
+}
+
+// getEqualExpression returns the expression comparing a and b of goType, which may be dereferenced
+// pointers such as *m.Name. Methods and fields are selected on the pointers themselves.
+func getEqualExpression(a, b, goType string) string {
+	if isByteSliceType(goType) {
+		return fmt.Sprintf("bytes.Equal(%s, %s)", a, b)
+	}
+	switch goType {
+	case "net.IPNet":
+		// IPNet holds slices, so it is not comparable
+		a, b = strings.TrimPrefix(a, "*"), strings.TrimPrefix(b, "*")
+		return fmt.Sprintf("bytes.Equal(%s.IP, %s.IP) && bytes.Equal(%s.Mask, %s.Mask)", a, b, a, b)
+	case "time.Time", "decimal.Decimal":
+		return fmt.Sprintf("%s.Equal(%s)", strings.TrimPrefix(a, "*"), b)
+	case "sql.NullTime":
+		a, b = strings.TrimPrefix(a, "*"), strings.TrimPrefix(b, "*")
+		return fmt.Sprintf("%s.Valid == %s.Valid && %s.Time.Equal(%s.Time)", a, b, a, b)
+	}
+	return fmt.Sprintf("%s == %s", a, b)
+}
@@ -0,0 +1,228 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// typeCheck parses and type checks the generated source files of a package, importing the standard
+// library only.
+func typeCheck(t *testing.T, srcs ...string) {
+	t.Helper()
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i, src := range srcs {
+		file, err := parser.ParseFile(fset, fmt.Sprintf("model%d.go", i), src, 0)
+		if err != nil {
+			t.Fatalf("%s\n%s", err, src)
+		}
+		files = append(files, file)
+	}
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := config.Check("model", fset, files, nil); err != nil {
+		t.Fatalf("%s\n%s", err, strings.Join(srcs, "\n"))
+	}
+}
+
+// runGoProgram runs the files, of package main importing the standard library only, with the go
+// command and returns what it prints. The test is skipped without the go command or with -short.
+func runGoProgram(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping go run with -short")
+	}
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+	dir := t.TempDir()
+	files["go.mod"] = "module model\n\ngo 1.18\n"
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goCommand, "run", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run: %s\n%s", err, output)
+	}
+	return string(output)
+}
+
+func TestWriteEqualMethod(t *testing.T) {
+	fields := []modelField{
+		{name: "Name", goType: "*string"},
+		{name: "Data", goType: "[]byte"},
+		{name: "CreatedAt", goType: "time.Time"},
+		{name: "DeletedAt", goType: "*time.Time"},
+		{name: "Network", goType: "net.IPNet"},
+		{name: "Gateway", goType: "*net.IPNet"},
+		{name: "Chunks", goType: "[][]byte"},
+		{name: "Tags", goType: "[]string"},
+	}
+	var buf bytes.Buffer
+	if usesBytes := writeEqualMethod(&buf, "Model", fields); !usesBytes {
+		t.Error("writeEqualMethod() = false, want the bytes package used")
+	}
+	for _, want := range []string{
+		"(m.Name == nil) == (other.Name == nil) && (m.Name == nil || *m.Name == *other.Name)",
+		"bytes.Equal(m.Data, other.Data)",
+		"m.CreatedAt.Equal(other.CreatedAt)",
+		"m.DeletedAt.Equal(*other.DeletedAt)",
+		"bytes.Equal(m.Network.IP, other.Network.IP) && bytes.Equal(m.Network.Mask, other.Network.Mask)",
+		"bytes.Equal(m.Chunks[i], other.Chunks[i])",
+		"m.Tags[i] == other.Tags[i]",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Equal method %q, want %s", buf.String(), want)
+		}
+	}
+
+	var src strings.Builder
+	src.WriteString("package model\n\nimport (\n\t\"bytes\"\n\t\"net\"\n\t\"time\"\n)\n\ntype Model struct {\n")
+	for _, field := range fields {
+		src.WriteString("\t" + field.name + " " + field.goType + "\n")
+	}
+	src.WriteString("}\n\n")
+	src.Write(buf.Bytes())
+	typeCheck(t, src.String())
+}
+
+func TestWriteEqualMethodNamedByteSlices(t *testing.T) {
+	if got, want := getEqualExpression("m.Shape", "other.Shape", "sqlingo.WellKnownBinary"), "bytes.Equal(m.Shape, other.Shape)"; got != want {
+		t.Errorf("getEqualExpression() = %s, want %s", got, want)
+	}
+	var buf bytes.Buffer
+	if usesBytes := writeEqualMethod(&buf, "Model", []modelField{{name: "ID", goType: "int64"}}); usesBytes {
+		t.Error("writeEqualMethod() = true without byte slices")
+	}
+}
+
+func TestGetJSONTag(t *testing.T) {
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		goType          string
+		stringInts      bool
+		omitEmpty       bool
+		tag             string
+	}{
+		{FieldDescriptor{Name: "id", Type: "bigint"}, "int64", false, false, "id"},
+		{FieldDescriptor{Name: "id", Type: "bigint"}, "int64", true, false, "id,string"},
+		{FieldDescriptor{Name: "id", Type: "bigint unsigned"}, "uint64", true, true, "id,string"},
+		{FieldDescriptor{Name: "count", Type: "int"}, "int32", true, false, "count"},
+		{FieldDescriptor{Name: "name", Type: "text", AllowNull: true}, "*string", false, true, "name,omitempty"},
+		{FieldDescriptor{Name: "name", Type: "text", AllowNull: true}, "*string", false, false, "name"},
+		{FieldDescriptor{Name: "parent_id", Type: "bigint", AllowNull: true}, "*int64", true, true, "parent_id,omitempty,string"},
+	}
+	for _, test := range tests {
+		if tag := getJSONTag(test.fieldDescriptor, test.goType, test.stringInts, test.omitEmpty); tag != test.tag {
+			t.Errorf("getJSONTag(%s, %s, %v, %v) = %s, want %s", test.fieldDescriptor.Name, test.goType, test.stringInts, test.omitEmpty, tag, test.tag)
+		}
+	}
+}
+
+func TestWriteStringMethod(t *testing.T) {
+	tests := []struct {
+		fields  []modelField
+		imports string
+	}{
+		{[]modelField{{name: "ID", goType: "int64"}, {name: "Name", goType: "*string"}, {name: "Data", goType: "[]byte"}}, "import (\n\t\"fmt\"\n\t\"strings\"\n)\n\n"},
+		{nil, "import \"strings\"\n\n"},
+	}
+	for _, test := range tests {
+		var buf bytes.Buffer
+		if usesFmt := writeStringMethod(&buf, "Model", test.fields); usesFmt != (len(test.fields) != 0) {
+			t.Errorf("writeStringMethod() = %v with %d fields", usesFmt, len(test.fields))
+		}
+		var src strings.Builder
+		src.WriteString("package model\n\n" + test.imports + "type Model struct {\n")
+		for _, field := range test.fields {
+			src.WriteString("\t" + field.name + " " + field.goType + "\n")
+		}
+		src.WriteString("}\n\n")
+		src.Write(buf.Bytes())
+		typeCheck(t, src.String())
+	}
+}
+
+func TestWriteSQLHelpers(t *testing.T) {
+	fields := []modelField{
+		{name: "ID", goType: "int64", fieldDescriptor: FieldDescriptor{Name: "id"}},
+		{name: "Name", goType: "*string", fieldDescriptor: FieldDescriptor{Name: "name", AllowNull: true}},
+		{name: "FullName", goType: "*string", fieldDescriptor: FieldDescriptor{Name: "full_name", IsGenerated: true}},
+		{name: "CreatedAt", goType: "time.Time", fieldDescriptor: FieldDescriptor{Name: "created_at"}},
+	}
+	var buf bytes.Buffer
+	writeSQLHelpers(&buf, "Model", fields, "any")
+	for _, want := range []string{
+		"var ModelColumns = []string{\"id\", \"name\", \"created_at\"}\n",
+		"\treturn []any{m.ID, m.Name, m.CreatedAt}\n",
+		"\treturn []any{&m.ID, &m.Name, &m.CreatedAt}\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("SQL helpers %q, want %q", buf.String(), want)
+		}
+	}
+
+	var src strings.Builder
+	src.WriteString("package model\n\nimport \"time\"\n\ntype Model struct {\n")
+	for _, field := range fields {
+		src.WriteString("\t" + field.name + " " + field.goType + "\n")
+	}
+	src.WriteString("}\n\n")
+	src.Write(buf.Bytes())
+	typeCheck(t, src.String())
+}
+
+func TestScanDest(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, score REAL NOT NULL)")
+	files := generateTestFiles(t, dataSourceName, Config{Package: "main", SQLHelpers: true})
+	files["main.go"] = `package main
+
+import (
+	"fmt"
+	"reflect"
+)
+
+func main() {
+	var users Users
+	dest := users.ScanDest()
+	fmt.Println(len(dest), len(UsersColumns), reflect.TypeOf(users).NumField())
+	for i, d := range dest {
+		fmt.Println(reflect.ValueOf(d).Pointer() == reflect.ValueOf(&users).Elem().Field(i).Addr().Pointer())
+	}
+}
+`
+	if output, want := runGoProgram(t, files), "3 3 3\ntrue\ntrue\ntrue\n"; output != want {
+		t.Errorf("ScanDest printed %q, want %q", output, want)
+	}
+}
+
+func TestFieldMap(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL, title TEXT)")
+	files := generateTestFiles(t, dataSourceName, Config{Package: "main", FieldMap: true, ForceCases: []string{"ID"}})
+	files["main.go"] = `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(PostsFieldColumns)
+}
+`
+	if output, want := runGoProgram(t, files), "map[ID:id Title:title UserID:user_id]\n"; output != want {
+		t.Errorf("PostsFieldColumns is %q, want %q", output, want)
+	}
+}
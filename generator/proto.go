@@ -0,0 +1,84 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const protoTimestampImport = "google/protobuf/timestamp.proto"
+
+// protoFile accumulates the messages of the .proto file written with -proto.
+type protoFile struct {
+	path     string
+	imports  []string
+	messages bytes.Buffer
+}
+
+// getProtoType returns the proto scalar type of a Go type, and whether there is one.
+func getProtoType(goType string, fieldDescriptor FieldDescriptor) (protoType string, ok bool) {
+	switch strings.TrimPrefix(goType, "*") {
+	case "int8", "int16", "int32":
+		return "int32", true
+	case "int", "int64":
+		return "int64", true
+	case "uint8", "uint16", "uint32":
+		return "uint32", true
+	case "uint", "uint64":
+		return "uint64", true
+	case "float32":
+		return "float", true
+	case "float64":
+		return "double", true
+	case "bool":
+		return "bool", true
+	case "string", "net.IP":
+		return "string", true
+	case "[]byte", "json.RawMessage":
+		return "bytes", true
+	case "time.Time":
+		return "google.protobuf.Timestamp", true
+	}
+	if len(fieldDescriptor.EnumValues) != 0 {
+		// string types generated with -enums
+		return "string", true
+	}
+	return "", false
+}
+
+// addMessage adds the message of a table, numbering the fields by columnNumbers.
+func (f *protoFile) addMessage(messageName string, fields []modelField, columnNumbers map[string]int) {
+	f.messages.WriteString(fmt.Sprintf("message %s {\n", messageName))
+	for _, field := range fields {
+		protoType, ok := getProtoType(field.goType, field.fieldDescriptor)
+		if !ok {
+			f.messages.WriteString(fmt.Sprintf("  // %s: no proto type for %s\n", field.fieldDescriptor.Name, field.goType))
+			continue
+		}
+		if protoType == "google.protobuf.Timestamp" {
+			f.imports = appendImports(f.imports, protoTimestampImport)
+		}
+		label := ""
+		if strings.HasPrefix(field.goType, "*") {
+			label = "optional "
+		}
+		f.messages.WriteString(fmt.Sprintf("  %s%s %s = %d;\n", label, protoType, ensureIdentifier(convertToSnakeCase(field.fieldDescriptor.Name)), columnNumbers[field.fieldDescriptor.Name]))
+	}
+	f.messages.WriteString("}\n\n")
+}
+
+func (f *protoFile) write(dbName string, options options) error {
+	var buf bytes.Buffer
+	buf.WriteString(options.marker + "\n\n")
+	buf.WriteString("syntax = \"proto3\";\n\n")
+	buf.WriteString(fmt.Sprintf("package %s;\n\n", ensureIdentifier(dbName)))
+	for _, protoImport := range f.imports {
+		buf.WriteString(fmt.Sprintf("import %q;\n", protoImport))
+	}
+	if len(f.imports) != 0 {
+		buf.WriteString("\n")
+	}
+	buf.Write(bytes.TrimSuffix(f.messages.Bytes(), []byte("\n")))
+	return writeOutputFile(&buf, f.path, filepath.Base(f.path), options)
+}
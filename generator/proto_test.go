@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGetProtoType(t *testing.T) {
+	tests := []struct {
+		goType          string
+		fieldDescriptor FieldDescriptor
+		protoType       string
+		ok              bool
+	}{
+		{"int8", FieldDescriptor{}, "int32", true},
+		{"*int32", FieldDescriptor{}, "int32", true},
+		{"int64", FieldDescriptor{}, "int64", true},
+		{"uint16", FieldDescriptor{}, "uint32", true},
+		{"uint64", FieldDescriptor{}, "uint64", true},
+		{"float32", FieldDescriptor{}, "float", true},
+		{"float64", FieldDescriptor{}, "double", true},
+		{"bool", FieldDescriptor{}, "bool", true},
+		{"*string", FieldDescriptor{}, "string", true},
+		{"[]byte", FieldDescriptor{}, "bytes", true},
+		{"json.RawMessage", FieldDescriptor{}, "bytes", true},
+		{"time.Time", FieldDescriptor{}, "google.protobuf.Timestamp", true},
+		{"UsersStatus", FieldDescriptor{EnumValues: []string{"active"}}, "string", true},
+		{"decimal.Decimal", FieldDescriptor{}, "", false},
+	}
+	for _, test := range tests {
+		if protoType, ok := getProtoType(test.goType, test.fieldDescriptor); protoType != test.protoType || ok != test.ok {
+			t.Errorf("getProtoType(%s) = %s, %v, want %s, %v", test.goType, protoType, ok, test.protoType, test.ok)
+		}
+	}
+}
+
+func TestProtoAddMessage(t *testing.T) {
+	var f protoFile
+	fields := []modelField{
+		{name: "Name", goType: "*string", fieldDescriptor: FieldDescriptor{Name: "name"}},
+		{name: "ID", goType: "int64", fieldDescriptor: FieldDescriptor{Name: "id"}},
+		{name: "CreatedAt", goType: "time.Time", fieldDescriptor: FieldDescriptor{Name: "createdAt"}},
+		{name: "Price", goType: "decimal.Decimal", fieldDescriptor: FieldDescriptor{Name: "price"}},
+	}
+	f.addMessage("Users", fields, map[string]int{"id": 1, "name": 2, "createdAt": 3, "price": 4})
+	want := "message Users {\n  optional string name = 2;\n  int64 id = 1;\n  google.protobuf.Timestamp created_at = 3;\n  // price: no proto type for decimal.Decimal\n}\n\n"
+	if got := f.messages.String(); got != want {
+		t.Errorf("addMessage() wrote %q, want %q", got, want)
+	}
+	if len(f.imports) != 1 || !strings.HasSuffix(f.imports[0], "timestamp.proto") {
+		t.Errorf("addMessage() imports %v, want the timestamp", f.imports)
+	}
+}
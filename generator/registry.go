@@ -0,0 +1,64 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// registryFileName is the name, without extension, of the file generated with -registry.
+const registryFileName = "sqlmodel_registry"
+
+// registry accumulates the metadata of the generated tables for the Tables map of -registry.
+type registry struct {
+	entries bytes.Buffer
+}
+
+func (r *registry) add(tableName, structName string, fields []modelField) {
+	r.entries.WriteString(fmt.Sprintf("\t%q: {\n", tableName))
+	r.entries.WriteString(fmt.Sprintf("\t\tName:   %q,\n", tableName))
+	r.entries.WriteString(fmt.Sprintf("\t\tGoType: %q,\n", structName))
+	r.entries.WriteString("\t\tColumns: []ColumnMeta{\n")
+	var primaryKeys []FieldDescriptor
+	for _, field := range fields {
+		r.entries.WriteString(fmt.Sprintf("\t\t\t{Name: %q, GoName: %q, GoType: %q},\n", field.fieldDescriptor.Name, field.name, field.goType))
+		if field.fieldDescriptor.PrimaryKeyOrdinal != 0 {
+			primaryKeys = append(primaryKeys, field.fieldDescriptor)
+		}
+	}
+	r.entries.WriteString("\t\t},\n")
+	if len(primaryKeys) != 0 {
+		sort.Slice(primaryKeys, func(i, j int) bool {
+			return primaryKeys[i].PrimaryKeyOrdinal < primaryKeys[j].PrimaryKeyOrdinal
+		})
+		var names []string
+		for _, primaryKey := range primaryKeys {
+			names = append(names, fmt.Sprintf("%q", primaryKey.Name))
+		}
+		r.entries.WriteString(fmt.Sprintf("\t\tPrimaryKeys: []string{%s},\n", strings.Join(names, ", ")))
+	}
+	r.entries.WriteString("\t},\n")
+}
+
+func (r *registry) write(dbName string, options options) error {
+	var buf bytes.Buffer
+	buf.WriteString("// TableMeta describes a generated table.\n")
+	buf.WriteString("type TableMeta struct {\n")
+	buf.WriteString("\tName        string\n")
+	buf.WriteString("\tGoType      string\n")
+	buf.WriteString("\tColumns     []ColumnMeta\n")
+	buf.WriteString("\tPrimaryKeys []string\n")
+	buf.WriteString("}\n\n")
+	buf.WriteString("// ColumnMeta describes a column of a generated table.\n")
+	buf.WriteString("type ColumnMeta struct {\n")
+	buf.WriteString("\tName   string\n")
+	buf.WriteString("\tGoName string\n")
+	buf.WriteString("\tGoType string\n")
+	buf.WriteString("}\n\n")
+	buf.WriteString("// Tables maps the names of the generated tables to their metadata.\n")
+	buf.WriteString("var Tables = map[string]TableMeta{\n")
+	buf.Write(r.entries.Bytes())
+	buf.WriteString("}\n\n")
+	return writeSource(dbName, "", registryFileName, nil, buf.Bytes(), options)
+}
@@ -0,0 +1,29 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE user_roles (role_id INTEGER, user_id INTEGER, PRIMARY KEY (user_id, role_id))",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY)",
+	)
+	file := generateTestFiles(t, dataSourceName, Config{Registry: true, Tables: []string{"users", "user_roles"}})[registryFileName+".go"]
+	for _, want := range []string{
+		"\t\"users\": {\n\t\tName:   \"users\",\n\t\tGoType: \"Users\",\n",
+		"\t\t\t{Name: \"name\", GoName: \"Name\", GoType: \"*string\"},\n",
+		"\t\"user_roles\": {\n",
+		"\t\tPrimaryKeys: []string{\"user_id\", \"role_id\"},\n",
+	} {
+		if !strings.Contains(file, want) {
+			t.Errorf("sqlmodel_registry.go = %q, want %q", file, want)
+		}
+	}
+	if strings.Count(file, "\t\tName: ") != 2 || strings.Contains(file, "posts") {
+		t.Errorf("sqlmodel_registry.go = %q, want an entry per generated table", file)
+	}
+	typeCheck(t, file)
+}
@@ -0,0 +1,105 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ForeignKey is a single-column foreign key constraint of a table.
+type ForeignKey struct {
+	Column           string
+	ReferencedTable  string
+	ReferencedColumn string // empty if the primary key of ReferencedTable is referenced
+}
+
+// ForeignKeyFetcher is implemented by a SchemaFetcher which supports -relations.
+type ForeignKeyFetcher interface {
+	GetForeignKeys(tableName string) ([]ForeignKey, error)
+}
+
+// queryForeignKeys queries the constraint name, column, referenced table and referenced column of
+// foreign keys. Composite foreign keys are left out as belongs-to fields need a single column.
+func queryForeignKeys(db queryer, query string, args ...interface{}) (foreignKeys []ForeignKey, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+	var constraintNames []string
+	columnCounts := make(map[string]int)
+	for rows.Next() {
+		var constraintName string
+		var foreignKey ForeignKey
+		var referencedColumn *string
+		if err = rows.Scan(&constraintName, &foreignKey.Column, &foreignKey.ReferencedTable, &referencedColumn); err != nil {
+			return
+		}
+		if referencedColumn != nil {
+			foreignKey.ReferencedColumn = *referencedColumn
+		}
+		constraintNames = append(constraintNames, constraintName)
+		columnCounts[constraintName]++
+		foreignKeys = append(foreignKeys, foreignKey)
+	}
+	if err = rows.Err(); err != nil {
+		return
+	}
+	result := foreignKeys[:0]
+	for i, foreignKey := range foreignKeys {
+		if columnCounts[constraintNames[i]] == 1 {
+			result = append(result, foreignKey)
+		}
+	}
+	return result, nil
+}
+
+// getRelationFields returns a belongs-to field with a gorm association tag for each foreign key of
+// the table to the generated tables.
+func getRelationFields(tableName string, fields []modelField, foreignKeys []ForeignKey, options options) (relationFields []FieldInfo) {
+	fieldNames := make(map[string]string)
+	usedNames := make(map[string]bool)
+	for _, field := range fields {
+		fieldNames[field.fieldDescriptor.Name] = field.name
+		usedNames[field.name] = true
+	}
+	for _, foreignKey := range foreignKeys {
+		foreignKeyField, ok := fieldNames[foreignKey.Column]
+		if !ok {
+			continue
+		}
+		if !containsString(options.tableNames, foreignKey.ReferencedTable) {
+			options.logger.Printf("warning: skip relation %s.%s: table %s is not generated", tableName, foreignKey.Column, foreignKey.ReferencedTable)
+			continue
+		}
+		referencedStruct := getStructName(foreignKey.ReferencedTable, options)
+
+		name := foreignKey.Column
+		for _, suffix := range []string{"_id", "_ID", "Id", "ID"} {
+			if len(name) > len(suffix) && strings.HasSuffix(name, suffix) {
+				name = strings.TrimSuffix(name, suffix)
+				break
+			}
+		}
+		goName := convertToExportedIdentifier(name, options.forceCases)
+		if name == foreignKey.Column {
+			goName = referencedStruct
+			name = convertToSnakeCase(referencedStruct)
+		}
+		if usedNames[goName] {
+			options.logger.Printf("warning: skip relation %s.%s: field %s already exists", tableName, foreignKey.Column, goName)
+			continue
+		}
+		usedNames[goName] = true
+
+		gormTag := "foreignKey:" + foreignKeyField
+		if foreignKey.ReferencedColumn != "" && !strings.EqualFold(foreignKey.ReferencedColumn, "id") {
+			gormTag += ";references:" + convertToExportedIdentifier(foreignKey.ReferencedColumn, options.forceCases)
+		}
+		tags := []string{fmt.Sprintf("gorm:\"%s\"", gormTag)}
+		if containsString(options.tags, "json") {
+			tags = append(tags, fmt.Sprintf("json:\"%s,omitempty\"", name))
+		}
+		relationFields = append(relationFields, FieldInfo{Name: goName, GoType: "*" + referencedStruct, Tag: strings.Join(tags, " ")})
+	}
+	return
+}
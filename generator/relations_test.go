@@ -0,0 +1,25 @@
+package generator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRelations(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), title TEXT)",
+	)
+	files := generateTestFiles(t, dataSourceName, Config{Relations: true, Tags: []string{"gorm"}, ForceCases: []string{"ID"}})
+	if want := "\tUser *Users `gorm:\"foreignKey:UserID\"`\n"; !strings.Contains(files["posts.go"], want) {
+		t.Errorf("posts.go = %q, want %q", files["posts.go"], want)
+	}
+	if strings.Contains(files["users.go"], "foreignKey") {
+		t.Errorf("users.go = %q, want no relation", files["users.go"])
+	}
+
+	files = generateTestFiles(t, dataSourceName, Config{Tags: []string{"gorm"}})
+	if strings.Contains(files["posts.go"], "foreignKey") {
+		t.Errorf("posts.go = %q, want no relation without -relations", files["posts.go"])
+	}
+}
@@ -0,0 +1,79 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// sqlingoImportPath is the import path of the sqlingo package used with -orm sqlingo.
+const sqlingoImportPath = "github.com/lqs/sqlingo"
+
+// getSqlingoFieldKind returns the kind of the sqlingo field for the Go type of a column, such as
+// Number for sqlingo.NumberField.
+func getSqlingoFieldKind(goType string) string {
+	goType = strings.TrimPrefix(goType, "*")
+	switch {
+	case goType == "bool":
+		return "Boolean"
+	case strings.HasPrefix(goType, "int"), strings.HasPrefix(goType, "uint"), strings.HasPrefix(goType, "float"):
+		return "Number"
+	default:
+		return "String"
+	}
+}
+
+// writeSqlingoModel writes the sqlingo table of the model: a t<Struct> type with a field of each
+// column, its <Struct>Table variable to build queries with, and the GetTable and GetValues methods
+// making the struct a sqlingo.Model.
+func writeSqlingoModel(buf *bytes.Buffer, tableName, className string, fields []modelField, emptyInterface string) {
+	tableType, tableVar, tableObj := "t"+className, className+"Table", "o"+className
+
+	buf.WriteString(fmt.Sprintf("func (m %s) GetTable() sqlingo.Table {\n", className))
+	buf.WriteString(fmt.Sprintf("\treturn %s\n", tableVar))
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (m %s) GetValues() []%s {\n", className, emptyInterface))
+	buf.WriteString(fmt.Sprintf("\treturn []%s{", emptyInterface))
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("m." + field.name)
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", tableType))
+	buf.WriteString("\tsqlingo.Table\n")
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("\t%s f%s%s\n", field.name, className, field.name))
+	}
+	buf.WriteString("}\n\n")
+
+	for _, field := range fields {
+		buf.WriteString(fmt.Sprintf("type f%s%s struct{ sqlingo.%sField }\n", className, field.name, getSqlingoFieldKind(field.goType)))
+	}
+	buf.WriteString("\n")
+
+	buf.WriteString(fmt.Sprintf("var %s = sqlingo.NewTable(%q)\n\n", tableObj, tableName))
+	buf.WriteString(fmt.Sprintf("// %s is the sqlingo table of %s.\n", tableVar, className))
+	buf.WriteString(fmt.Sprintf("var %s = %s{\n", tableVar, tableType))
+	buf.WriteString(fmt.Sprintf("\tTable: %s,\n", tableObj))
+	for _, field := range fields {
+		kind := getSqlingoFieldKind(field.goType)
+		buf.WriteString(fmt.Sprintf("\t%s: f%s%s{sqlingo.New%sField(%s, %q)},\n", field.name, className, field.name, kind, tableObj, field.fieldDescriptor.Name))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("func (t %s) GetFields() []sqlingo.Field {\n", tableType))
+	buf.WriteString("\treturn []sqlingo.Field{")
+	for i, field := range fields {
+		if i != 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString("t." + field.name)
+	}
+	buf.WriteString("}\n")
+	buf.WriteString("}\n\n")
+}
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGetSqlingoFieldKind(t *testing.T) {
+	tests := []struct {
+		goType string
+		kind   string
+	}{
+		{"bool", "Boolean"},
+		{"*bool", "Boolean"},
+		{"int64", "Number"},
+		{"*uint32", "Number"},
+		{"float64", "Number"},
+		{"string", "String"},
+		{"time.Time", "String"},
+	}
+	for _, test := range tests {
+		if kind := getSqlingoFieldKind(test.goType); kind != test.kind {
+			t.Errorf("getSqlingoFieldKind(%s) = %s, want %s", test.goType, kind, test.kind)
+		}
+	}
+}
+
+func TestSqlingoModel(t *testing.T) {
+	schemaFetcher := testSchemaFetcher{dbName: "app", tables: map[string][]FieldDescriptor{"users": {
+		{Name: "id", Type: "bigint", PrimaryKeyOrdinal: 1},
+		{Name: "name", Type: "text", AllowNull: true},
+		{Name: "active", Type: "bool"},
+	}}}
+	output := t.TempDir()
+	if err := GenerateWithFetcher(schemaFetcher, Config{Output: output, ORM: "sqlingo", Logger: discardLogger{}}); err != nil {
+		t.Fatal(err)
+	}
+	file := readTestFiles(t, output)["users.go"]
+	for _, want := range []string{
+		"import \"github.com/lqs/sqlingo\"",
+		"func (m Users) GetTable() sqlingo.Table {\n\treturn UsersTable\n}",
+		"func (m Users) GetValues() []any {\n\treturn []any{m.Id, m.Name, m.Active}\n}",
+		"type tUsers struct {\n\tsqlingo.Table\n\tId fUsersId\n\tName fUsersName\n\tActive fUsersActive\n}",
+		"type fUsersId struct{ sqlingo.NumberField }\ntype fUsersName struct{ sqlingo.StringField }\ntype fUsersActive struct{ sqlingo.BooleanField }\n",
+		"var oUsers = sqlingo.NewTable(\"users\")",
+		"\tActive: fUsersActive{sqlingo.NewBooleanField(oUsers, \"active\")},\n",
+		"func (t tUsers) GetFields() []sqlingo.Field {\n\treturn []sqlingo.Field{t.Id, t.Name, t.Active}\n}",
+	} {
+		if !strings.Contains(file, want) {
+			t.Errorf("-orm sqlingo: users.go = %q, want %q", file, want)
+		}
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "users.go", file, 0); err != nil {
+		t.Errorf("-orm sqlingo: users.go doesn't parse: %s", err)
+	}
+
+	if _, err := newOptions("sqlite3", Config{ORM: "gorm"}); err == nil {
+		t.Error("newOptions() accepted the orm gorm")
+	}
+}
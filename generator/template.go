@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed model.tmpl
+var defaultTemplateText string
+
+// defaultTemplate renders the files of the tables without -template.
+var defaultTemplate = template.Must(template.New("model.tmpl").Parse(defaultTemplateText))
+
+// FieldInfo describes a field of a generated struct, resolved from its column before the struct is
+// rendered, by the built-in rendering or by -template.
+type FieldInfo struct {
+	// Go name of the field
+	Name   string
+	GoType string
+	// struct tag without back quotes
+	Tag      string
+	Column   string
+	SQLType  string
+	Nullable bool
+	Comment  string
+	// import paths used by GoType
+	Imports []string
+	// lines of the comment written above the field, without the leading //
+	Comments []string
+	// comment written after the field, such as the precision or the default of the column
+	TrailingComment string
+	// whether the field is a column of the -embed struct, which declares it instead of the model
+	Embedded bool
+}
+
+func newFieldInfo(fieldDescriptor FieldDescriptor, goName, goType string, imports []string, options options) FieldInfo {
+	fieldInfo := FieldInfo{
+		Name:     goName,
+		GoType:   goType,
+		Tag:      strings.Trim(getTag(fieldDescriptor, goType, options), "`"),
+		Column:   fieldDescriptor.Name,
+		SQLType:  fieldDescriptor.Type,
+		Nullable: fieldDescriptor.AllowNull,
+		Comment:  fieldDescriptor.Comment,
+		Imports:  imports,
+	}
+	isRenamed := !strings.EqualFold(goName, fieldDescriptor.Name)
+	if fieldDescriptor.Comment != "" {
+		comment := strings.ReplaceAll(fieldDescriptor.Comment, "\n", " ")
+		if isRenamed {
+			comment += fmt.Sprintf(" (column: %s)", fieldDescriptor.Name)
+		}
+		fieldInfo.Comments = append(fieldInfo.Comments, comment)
+	} else if options.preserveNames && isRenamed {
+		fieldInfo.Comments = append(fieldInfo.Comments, "column: "+fieldDescriptor.Name)
+	}
+	if options.noComments {
+		return fieldInfo
+	}
+	var trailingComments []string
+	if fieldDescriptor.Precision != 0 {
+		// the Go type may not keep the scale, such as float64
+		trailingComments = append(trailingComments, fmt.Sprintf("%s(%d,%d)", strings.ToLower(fieldDescriptor.Type), fieldDescriptor.Precision, fieldDescriptor.Scale))
+	}
+	if dimensions := strings.Count(fieldDescriptor.Type, "[]"); dimensions > 1 {
+		trailingComments = append(trailingComments, fmt.Sprintf("%d-dimensional array", dimensions))
+	}
+	if hasDefault(fieldDescriptor) && !hasGormDefault(fieldDescriptor, options) {
+		trailingComments = append(trailingComments, "default: "+strings.ReplaceAll(*fieldDescriptor.DefaultValue, "\n", " "))
+	}
+	fieldInfo.TrailingComment = strings.Join(trailingComments, ", ")
+	return fieldInfo
+}
+
+// TemplateData is given to the template rendering the file of a table, the default one or the one
+// of -template.
+type TemplateData struct {
+	// lines written before the package clause: the build constraints, the -header file and the
+	// marker of generated files
+	Header      string
+	PackageName string
+	StructName  string
+	TableName   string
+	// whether the table is a view
+	IsView bool
+	// name of the -embed struct embedded in the struct, empty if it is not
+	Embedded string
+	// all the columns, including the ones of Embedded
+	Fields []FieldInfo
+	// notes of the columns left out by -onunknown skip, such as "data: unknown field type blob"
+	SkippedColumns []string
+	// belongs-to fields of -relations
+	Relations []FieldInfo
+	// import paths used by the fields, leaving out the ones of Embedded
+	Imports []string
+	// source of the declarations following the struct: the enum types, the TableName method and the
+	// methods and variables of the options such as -sqlhelpers or -stringer
+	Methods string
+	// import paths used by Methods
+	MethodImports []string
+}
+
+// FileImports returns the sorted import paths of the fields and the methods.
+func (d TemplateData) FileImports() []string {
+	imports := appendImports(append([]string(nil), d.Imports...), d.MethodImports...)
+	sort.Strings(imports)
+	return imports
+}
+
+// parseTemplateFile parses the file of -template along with the templates defined by the default
+// template, which it can use or redefine.
+func parseTemplateFile(templateFile string) (*template.Template, error) {
+	content, err := os.ReadFile(templateFile)
+	if err != nil {
+		return nil, err
+	}
+	t, err := defaultTemplate.Clone()
+	if err != nil {
+		return nil, err
+	}
+	if t, err = t.New(filepath.Base(templateFile)).Parse(string(content)); err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return t, nil
+}
+
+// writeTemplateSource renders the file of a table with the template of the options, the -template
+// one or the default one.
+func writeTemplateSource(fileName string, data TemplateData, options options) error {
+	var buf bytes.Buffer
+	if err := options.template.Execute(&buf, data); err != nil {
+		return fmt.Errorf("%s: %w", data.TableName, err)
+	}
+	processed, err := postProcess(data.TableName, &buf, options)
+	if err != nil {
+		return err
+	}
+	return writeOutputFile(processed, fmt.Sprintf("%s/%s.go", options.outputPath, fileName), data.TableName, options)
+}
@@ -0,0 +1,192 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewFieldInfo(t *testing.T) {
+	options, err := newOptions("postgres", Config{Tags: []string{"json"}, PreserveNames: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defaultValue := "now()"
+	tests := []struct {
+		fieldDescriptor FieldDescriptor
+		fieldInfo       FieldInfo
+	}{
+		{
+			FieldDescriptor{Name: "created_at", Type: "timestamp", DefaultValue: &defaultValue},
+			FieldInfo{Name: "CreatedAt", GoType: "time.Time", Tag: `json:"created_at"`, Column: "created_at", SQLType: "timestamp", Imports: []string{"time"}, Comments: []string{"column: created_at"}, TrailingComment: "default: now()"},
+		},
+		{
+			FieldDescriptor{Name: "nickname", Type: "varchar", Size: 32, AllowNull: true, Comment: "shown\nto others"},
+			FieldInfo{Name: "Nickname", GoType: "*string", Tag: `json:"nickname"`, Column: "nickname", SQLType: "varchar", Nullable: true, Comment: "shown\nto others", Comments: []string{"shown to others"}},
+		},
+		{
+			FieldDescriptor{Name: "price", Type: "numeric", Precision: 12, Scale: 2},
+			FieldInfo{Name: "Price", GoType: "string", Tag: `json:"price"`, Column: "price", SQLType: "numeric", TrailingComment: "numeric(12,2)"},
+		},
+	}
+	for _, test := range tests {
+		goType, imports, err := getType(test.fieldDescriptor, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fieldInfo := newFieldInfo(test.fieldDescriptor, convertToExportedIdentifier(test.fieldDescriptor.Name, nil), goType, imports, options)
+		if !reflect.DeepEqual(fieldInfo, test.fieldInfo) {
+			t.Errorf("newFieldInfo(%s) = %+v, want %+v", test.fieldDescriptor.Name, fieldInfo, test.fieldInfo)
+		}
+	}
+}
+
+func TestGenerateTableFieldInfos(t *testing.T) {
+	dataSourceName := newTestDatabase(t,
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL, name TEXT)",
+		"CREATE TABLE posts (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL)",
+	)
+	output := t.TempDir()
+	config := Config{Output: output, DataSourceName: dataSourceName, Embed: "Base:id,created_at", Manifest: output + "/manifest.json", Logger: discardLogger{}}
+	options, err := newOptions("sqlite3", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemaFetcher := newTestSchemaFetcher(t, dataSourceName, options)
+	if err = generateTable(schemaFetcher, "app", "users", options); err != nil {
+		t.Fatal(err)
+	}
+	fields := options.manifest.Tables[0].Fields
+	if len(fields) != 3 || fields[1].GoType != "time.Time" || fields[2].GoType != "*string" || !fields[2].Nullable {
+		t.Errorf("manifest fields %+v, want created_at time.Time and name *string", fields)
+	}
+	embeddedFields := options.embedded.fields
+	if len(embeddedFields) != 2 || !embeddedFields[0].Embedded || embeddedFields[1].Name != "CreatedAt" {
+		t.Errorf("embedded fields %+v, want id and created_at", embeddedFields)
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, created_at DATETIME NOT NULL, name TEXT)")
+	templateFile := filepath.Join(t.TempDir(), "model.tmpl")
+	content := `package {{.PackageName}}
+
+// {{.StructName}} of {{.TableName}}
+{{range .Fields}}// {{.Name}} {{.GoType}} {{.SQLType}}{{if .Nullable}} null{{end}}
+{{end}}{{range .Imports}}// import {{.}}
+{{end}}`
+	if err := os.WriteFile(templateFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file := generateTestFiles(t, dataSourceName, Config{Template: templateFile})["users.go"]
+	want := "package app\n\n// Users of users\n// Id int64 INTEGER\n// CreatedAt time.Time DATETIME\n// Name *string TEXT null\n// import time\n"
+	if file != want {
+		t.Errorf("users.go = %q, want %q", file, want)
+	}
+}
+
+func TestTemplateUsesDefaultTemplates(t *testing.T) {
+	dataSourceName := newTestDatabase(t, "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)")
+	defaultFile := generateTestFiles(t, dataSourceName, Config{Stringer: true})["users.go"]
+
+	templateFile := filepath.Join(t.TempDir(), "model.tmpl")
+	content := `{{template "file" .}}// {{.StructName}}Table is the table of {{.StructName}}.
+const {{.StructName}}Table = "{{.TableName}}"
+`
+	if err := os.WriteFile(templateFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file := generateTestFiles(t, dataSourceName, Config{Stringer: true, Template: templateFile})["users.go"]
+	if want := defaultFile + "// UsersTable is the table of Users.\nconst UsersTable = \"users\"\n"; file != want {
+		t.Errorf("users.go = %q, want %q", file, want)
+	}
+
+	// the templates of the default template can be redefined
+	content = `{{define "field"}}
+	{{.Name}} {{.GoType}} // {{.Column}}{{end}}{{template "file" .}}`
+	if err := os.WriteFile(templateFile, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	file = generateTestFiles(t, dataSourceName, Config{Template: templateFile})["users.go"]
+	if want := "type Users struct {\n\tId int64 // id\n\tName *string // name\n}\n"; !strings.Contains(file, want) {
+		t.Errorf("users.go = %q, want %q", file, want)
+	}
+}
+
+func TestDefaultTemplate(t *testing.T) {
+	data := TemplateData{
+		Header:      "// Code generated by sqlmodel; DO NOT EDIT.\n",
+		PackageName: "app",
+		StructName:  "Users",
+		TableName:   "users",
+		Embedded:    "Base",
+		Fields: []FieldInfo{
+			{Name: "Id", GoType: "int64", Tag: `json:"id"`, Embedded: true},
+			{Name: "UserName", GoType: "string", Tag: `json:"user_name"`, Comments: []string{"the name"}, TrailingComment: "default: ''"},
+			{Name: "DeletedAt", GoType: "*time.Time", Imports: []string{"time"}},
+		},
+		SkippedColumns: []string{"avatar: unknown field type blob"},
+		Relations:      []FieldInfo{{Name: "Team", GoType: "*Teams", Tag: `gorm:"foreignKey:TeamId"`}},
+		Imports:        []string{"time"},
+		Methods:        "func (m Users) TableName() string {\n\treturn \"users\"\n}\n\n",
+		MethodImports:  []string{"fmt"},
+	}
+	var buf strings.Builder
+	if err := defaultTemplate.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by sqlmodel; DO NOT EDIT.
+package app ` + `
+
+import (
+	"fmt"
+	"time"
+)
+
+type Users struct {
+	Base
+	// skipped avatar: unknown field type blob
+	// the name
+	UserName string ` + "`json:\"user_name\"`" + ` // default: ''
+	DeletedAt *time.Time ` + "``" + `
+	Team *Teams ` + "`gorm:\"foreignKey:TeamId\"`" + `
+}
+
+func (m Users) TableName() string {
+	return "users"
+}
+
+`
+	if buf.String() != want {
+		t.Errorf("default template = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestNewFieldInfoColumnComments(t *testing.T) {
+	tests := []struct {
+		name          string
+		comment       string
+		preserveNames bool
+		comments      []string
+	}{
+		{"created_at", "time of creation", false, []string{"time of creation (column: created_at)"}},
+		{"created_at", "time of creation", true, []string{"time of creation (column: created_at)"}},
+		{"created_at", "", false, nil},
+		{"created_at", "", true, []string{"column: created_at"}},
+		{"name", "display name", true, []string{"display name"}},
+		{"name", "", true, nil},
+	}
+	for _, test := range tests {
+		options, err := newOptions("mysql", Config{PreserveNames: test.preserveNames})
+		if err != nil {
+			t.Fatal(err)
+		}
+		fieldDescriptor := FieldDescriptor{Name: test.name, Type: "text", Comment: test.comment}
+		fieldInfo := newFieldInfo(fieldDescriptor, convertToExportedIdentifier(test.name, nil), "string", nil, options)
+		if !reflect.DeepEqual(fieldInfo.Comments, test.comments) {
+			t.Errorf("newFieldInfo(%s, %q, -preservenames %v).Comments = %q, want %q", test.name, test.comment, test.preserveNames, fieldInfo.Comments, test.comments)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package generator
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// timeType is the Go type of date and time columns given by -timetype.
+type timeType struct {
+	goType     string // qualified by the package name, such as time.Time
+	importPath string
+	// null handling types such as null.Time or sql.NullTime are not pointers for nullable columns
+	handlesNull bool
+}
+
+var (
+	versionSuffixRegexp = regexp.MustCompile(`\.v[0-9]+$`)
+	majorVersionRegexp  = regexp.MustCompile(`^v[0-9]+$`)
+)
+
+// parseTimeType parses a type qualified by its import path, such as github.com/myorg/types.Time.
+// The type is assumed to handle null if it is in a package named null or its name starts with Null.
+func parseTimeType(qualifiedType string) (t timeType, err error) {
+	if qualifiedType == "" {
+		qualifiedType = "time.Time"
+	}
+	dot := strings.LastIndex(qualifiedType, ".")
+	if dot <= 0 || dot == len(qualifiedType)-1 || dot < strings.LastIndex(qualifiedType, "/") || nonIdentifierRegexp.MatchString(qualifiedType[dot+1:]) {
+		err = fmt.Errorf("invalid time type %s", qualifiedType)
+		return
+	}
+	t.importPath, t.goType = qualifiedType[:dot], qualifiedType[dot+1:]
+	packageName := getPackageName(t.importPath)
+	t.handlesNull = packageName == "null" || strings.HasPrefix(t.goType, "Null")
+	t.goType = packageName + "." + t.goType
+	return
+}
+
+// getPackageName guesses the package name of an import path from its last element, leaving out
+// major versions such as in gopkg.in/guregu/null.v4 or github.com/myorg/types/v2.
+func getPackageName(importPath string) string {
+	name := path.Base(importPath)
+	if majorVersionRegexp.MatchString(name) && path.Dir(importPath) != "." {
+		name = path.Base(path.Dir(importPath))
+	}
+	return strings.ReplaceAll(versionSuffixRegexp.ReplaceAllString(name, ""), "-", "_")
+}
@@ -0,0 +1,61 @@
+package generator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTimeType(t *testing.T) {
+	tests := []struct {
+		qualifiedType string
+		timeType      timeType
+	}{
+		{"", timeType{goType: "time.Time", importPath: "time"}},
+		{"github.com/myorg/types.Time", timeType{goType: "types.Time", importPath: "github.com/myorg/types"}},
+		{"gopkg.in/guregu/null.v4.Time", timeType{goType: "null.Time", importPath: "gopkg.in/guregu/null.v4", handlesNull: true}},
+		{"database/sql.NullTime", timeType{goType: "sql.NullTime", importPath: "database/sql", handlesNull: true}},
+		{"github.com/myorg/go-types/v2.Time", timeType{goType: "go_types.Time", importPath: "github.com/myorg/go-types/v2"}},
+	}
+	for _, test := range tests {
+		timeType, err := parseTimeType(test.qualifiedType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if timeType != test.timeType {
+			t.Errorf("parseTimeType(%s) = %+v, want %+v", test.qualifiedType, timeType, test.timeType)
+		}
+	}
+	for _, qualifiedType := range []string{"Time", ".Time", "github.com/myorg/types.", "github.com/myorg.v2/types", "types.Time-1"} {
+		if _, err := parseTimeType(qualifiedType); err == nil {
+			t.Errorf("parseTimeType(%s) succeeded", qualifiedType)
+		}
+	}
+}
+
+func TestGetTypeTimeType(t *testing.T) {
+	tests := []struct {
+		timeType  string
+		fieldType string
+		allowNull bool
+		goType    string
+		imports   []string
+	}{
+		{"github.com/myorg/types.Time", "datetime", false, "types.Time", []string{"github.com/myorg/types"}},
+		{"github.com/myorg/types.Time", "timestamp", true, "*types.Time", []string{"github.com/myorg/types"}},
+		{"github.com/myorg/types.Time", "date", true, "*types.Time", []string{"github.com/myorg/types"}},
+		{"gopkg.in/guregu/null.v4.Time", "datetime", true, "null.Time", []string{"gopkg.in/guregu/null.v4"}},
+	}
+	for _, test := range tests {
+		options, err := newOptions("mysql", Config{TimeType: test.timeType})
+		if err != nil {
+			t.Fatal(err)
+		}
+		goType, imports, err := getType(FieldDescriptor{Name: "created_at", Type: test.fieldType, AllowNull: test.allowNull}, options)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if goType != test.goType || !reflect.DeepEqual(imports, test.imports) {
+			t.Errorf("getType(%s, -timetype %s, null %v) = %s, %q, want %s, %q", test.fieldType, test.timeType, test.allowNull, goType, imports, test.goType, test.imports)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package generator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// mysqlTLSConfigName is the name the TLS config of the -tls flags is registered as for MySQL.
+const mysqlTLSConfigName = "sqlmodel"
+
+// tlsOptions are the certificates given by the -tls flags.
+type tlsOptions struct {
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+}
+
+func (t tlsOptions) isSet() bool {
+	return t.caFile != "" || t.certFile != "" || t.keyFile != "" || t.serverName != ""
+}
+
+// applyTLS returns the data source name connecting with the TLS options: MySQL gets a registered
+// TLS config, Postgres the sslmode and certificate parameters of libpq.
+func applyTLS(driverName, dataSourceName string, t tlsOptions) (string, error) {
+	if !t.isSet() {
+		return dataSourceName, nil
+	}
+	if (t.certFile == "") != (t.keyFile == "") {
+		return "", errors.New("-tlscert and -tlskey must be given together")
+	}
+	switch driverName {
+	case "mysql":
+		config, err := t.newConfig()
+		if err != nil {
+			return "", err
+		}
+		if err = mysql.RegisterTLSConfig(mysqlTLSConfigName, config); err != nil {
+			return "", err
+		}
+		return appendDataSourceParameters(dataSourceName, "?", "&", "tls="+mysqlTLSConfigName), nil
+	case "postgres":
+		if t.serverName != "" {
+			return "", errors.New("-tlsservername is not supported by postgres, the host is verified")
+		}
+		sslMode := "require"
+		if t.caFile != "" {
+			sslMode = "verify-full"
+		}
+		parameters := map[string]string{"sslmode": sslMode, "sslrootcert": t.caFile, "sslcert": t.certFile, "sslkey": t.keyFile}
+		var pairs []string
+		for _, key := range []string{"sslmode", "sslrootcert", "sslcert", "sslkey"} {
+			if value := parameters[key]; value != "" {
+				if strings.Contains(dataSourceName, "://") {
+					pairs = append(pairs, key+"="+url.QueryEscape(value))
+				} else {
+					pairs = append(pairs, fmt.Sprintf("%s='%s'", key, strings.ReplaceAll(value, "'", `\'`)))
+				}
+			}
+		}
+		if strings.Contains(dataSourceName, "://") {
+			return appendDataSourceParameters(dataSourceName, "?", "&", pairs...), nil
+		}
+		return strings.TrimSpace(dataSourceName + " " + strings.Join(pairs, " ")), nil
+	}
+	return "", fmt.Errorf("the -tls flags are not supported by %s", driverName)
+}
+
+// appendDataSourceParameters appends parameters to a data source name, after querySeparator for
+// the first one.
+func appendDataSourceParameters(dataSourceName, querySeparator, separator string, parameters ...string) string {
+	for _, parameter := range parameters {
+		if strings.Contains(dataSourceName, querySeparator) {
+			dataSourceName += separator + parameter
+		} else {
+			dataSourceName += querySeparator + parameter
+		}
+	}
+	return dataSourceName
+}
+
+func (t tlsOptions) newConfig() (*tls.Config, error) {
+	config := &tls.Config{ServerName: t.serverName}
+	if t.caFile != "" {
+		pem, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", t.caFile)
+		}
+	}
+	if t.certFile != "" {
+		certificate, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	return config, nil
+}
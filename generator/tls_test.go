@@ -0,0 +1,127 @@
+package generator
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// writeSelfSignedCertificate writes a self-signed certificate and its key as PEM files in a
+// temporary directory and returns their paths.
+func writeSelfSignedCertificate(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "db.example.com"},
+		DNSNames:              []string{"db.example.com"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	if err = os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return
+}
+
+func TestApplyTLSMySQL(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCertificate(t)
+	tests := []struct {
+		dataSourceName string
+		want           string
+	}{
+		{"user:secret@tcp(db.example.com:3306)/app", "user:secret@tcp(db.example.com:3306)/app?tls=" + mysqlTLSConfigName},
+		{"user:secret@tcp(db.example.com:3306)/app?parseTime=true", "user:secret@tcp(db.example.com:3306)/app?parseTime=true&tls=" + mysqlTLSConfigName},
+	}
+	for _, test := range tests {
+		dataSourceName, err := applyTLS("mysql", test.dataSourceName, tlsOptions{caFile: certFile, certFile: certFile, keyFile: keyFile, serverName: "db.example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataSourceName != test.want {
+			t.Errorf("applyTLS(%s) = %s, want %s", test.dataSourceName, dataSourceName, test.want)
+		}
+		// the driver fails to parse the data source name of a TLS config not registered
+		if _, err = mysql.ParseDSN(dataSourceName); err != nil {
+			t.Error(err)
+		}
+	}
+
+	config, err := tlsOptions{caFile: certFile, certFile: certFile, keyFile: keyFile, serverName: "db.example.com"}.newConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ServerName != "db.example.com" || config.RootCAs == nil || len(config.Certificates) != 1 {
+		t.Errorf("newConfig() = %+v, want the certificates of the flags", config)
+	}
+}
+
+func TestApplyTLSPostgres(t *testing.T) {
+	tests := []struct {
+		dataSourceName string
+		tlsOptions     tlsOptions
+		want           string
+	}{
+		{"postgres://user@localhost/app", tlsOptions{caFile: "/etc/ca.pem"}, "postgres://user@localhost/app?sslmode=verify-full&sslrootcert=%2Fetc%2Fca.pem"},
+		{"postgres://user@localhost/app?connect_timeout=5", tlsOptions{certFile: "c.pem", keyFile: "k.pem"}, "postgres://user@localhost/app?connect_timeout=5&sslmode=require&sslcert=c.pem&sslkey=k.pem"},
+		{"host=localhost dbname=app", tlsOptions{caFile: "/etc/my ca.pem"}, "host=localhost dbname=app sslmode='verify-full' sslrootcert='/etc/my ca.pem'"},
+	}
+	for _, test := range tests {
+		dataSourceName, err := applyTLS("postgres", test.dataSourceName, test.tlsOptions)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dataSourceName != test.want {
+			t.Errorf("applyTLS(%s) = %s, want %s", test.dataSourceName, dataSourceName, test.want)
+		}
+	}
+}
+
+func TestApplyTLSErrors(t *testing.T) {
+	tests := []struct {
+		driverName string
+		tlsOptions tlsOptions
+	}{
+		{"mysql", tlsOptions{certFile: "cert.pem"}},
+		{"mysql", tlsOptions{caFile: filepath.Join(t.TempDir(), "missing.pem")}},
+		{"postgres", tlsOptions{serverName: "db.example.com"}},
+		{"sqlite3", tlsOptions{caFile: "ca.pem"}},
+	}
+	for _, test := range tests {
+		if _, err := applyTLS(test.driverName, "dsn", test.tlsOptions); err == nil {
+			t.Errorf("applyTLS(%s, %+v) succeeded", test.driverName, test.tlsOptions)
+		}
+	}
+	if dataSourceName, err := applyTLS("sqlite3", "app.db", tlsOptions{}); err != nil || dataSourceName != "app.db" {
+		t.Errorf("applyTLS() = %s, %v without TLS options", dataSourceName, err)
+	}
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	_ "github.com/ClickHouse/clickhouse-go"
+	"github.com/Ficoto/sqlmodel/generator"
+)
+
+func main() {
+	if err := generator.Generate("clickhouse", "tcp://localhost:9000?username=user&password=pass&database=db"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(generator.ExitCode(err))
+	}
+}
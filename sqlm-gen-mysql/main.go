@@ -1,13 +1,16 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/Ficoto/sqlmodel/generator"
 	_ "github.com/go-sql-driver/mysql"
 )
 
 func main() {
-	err := generator.Generate("mysql", "username:password@tcp(hostname:3306)/database")
-	if err != nil {
-		panic(err)
+	if err := generator.Generate("mysql", "username:password@tcp(hostname:3306)/database"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(generator.ExitCode(err))
 	}
 }
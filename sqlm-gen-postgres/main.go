@@ -1,13 +1,16 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/Ficoto/sqlmodel/generator"
 	_ "github.com/lib/pq"
 )
 
 func main() {
-	err := generator.Generate("postgres", "host=localhost port=5432 user=user password=pass dbname=db sslmode=disable")
-	if err != nil {
-		panic(err)
+	if err := generator.Generate("postgres", "host=localhost port=5432 user=user password=pass dbname=db sslmode=disable"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(generator.ExitCode(err))
 	}
 }
@@ -1,13 +1,16 @@
 package main
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/Ficoto/sqlmodel/generator"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
-	err := generator.Generate("mysql", "./testdb.sqlite3")
-	if err != nil {
-		panic(err)
+	if err := generator.Generate("sqlite3", "./testdb.sqlite3"); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(generator.ExitCode(err))
 	}
 }